@@ -0,0 +1,883 @@
+// Command apigen generates typed Proxmox VE API bindings from the API schema
+// shipped with pve-docs. It reads api-viewer/apidoc.js as is (the JS wrapper
+// around the schema array is skipped) or the bare apidoc.json array.
+//
+// It is run through go generate from the proxmox package:
+//
+//	go generate ./proxmox
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type schemaNode struct {
+	Path     string               `json:"path"`
+	Text     string               `json:"text"`
+	Info     map[string]*endpoint `json:"info"`
+	Children []*schemaNode        `json:"children"`
+}
+
+type endpoint struct {
+	Method      string  `json:"method"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Parameters  *schema `json:"parameters"`
+	Returns     *schema `json:"returns"`
+}
+
+type schema struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description"`
+	Optional    json.Number        `json:"optional"`
+	Enum        []string           `json:"enum"`
+	Minimum     *float64           `json:"minimum"`
+	Maximum     *float64           `json:"maximum"`
+	MaxLength   *int               `json:"maxLength"`
+	Pattern     string             `json:"pattern"`
+	Format      json.RawMessage    `json:"format"`
+	Properties  map[string]*schema `json:"properties"`
+	Items       *schema            `json:"items"`
+}
+
+type operation struct {
+	Path     string
+	Method   string
+	Name     string
+	Endpoint *endpoint
+}
+
+var methodVerbs = map[string]string{
+	"GET":    "Get",
+	"POST":   "Create",
+	"PUT":    "Update",
+	"DELETE": "Delete",
+}
+
+var methodOrder = []string{"GET", "POST", "PUT", "DELETE"}
+
+// formatChecks maps PVE string formats to Go expressions over {v} that are
+// true for valid values. Helpers are defined in the proxmox package.
+var formatChecks = map[string]string{
+	"ip":             "(isIPv4({v}) || isIPv6({v}))",
+	"ipv4":           "isIPv4({v})",
+	"ipv6":           "isIPv6({v})",
+	"CIDR":           "(isCIDR({v}, false) || isCIDR({v}, true))",
+	"CIDRv4":         "isCIDR({v}, false)",
+	"CIDRv6":         "isCIDR({v}, true)",
+	"mac-addr":       "macAddrRegexp.MatchString({v})",
+	"dns-name":       "isDNSName({v})",
+	"pve-node":       "dnsLabelRegexp.MatchString({v})",
+	"pve-poolid":     "poolNameRegexp.MatchString({v})",
+	"pve-tag":        "tagRegexp.MatchString({v})",
+	"pve-iface":      "ifaceNameRegexp.MatchString({v})",
+	"pve-storage-id": "storageIdRegexp.MatchString({v})",
+	"pve-vmid":       "isVmIdString({v})",
+}
+
+// indexedKeyRegexp matches schema keys like net[n] that stand for net0, net1, ...
+var indexedKeyRegexp = regexp.MustCompile(`^([a-z][a-z0-9_\-]*)\[n\]$`)
+
+// perlFlagsRegexp matches the "(?^flags:" group of stringified Perl qr// patterns.
+var perlFlagsRegexp = regexp.MustCompile(`\(\?\^([a-z]*):`)
+
+func main() {
+	schemaFile := flag.String("schema", "apidoc.json", "path to the PVE apidoc.js or apidoc.json schema")
+	outFile := flag.String("out", "zz_generated_api.go", "output Go file")
+	pkg := flag.String("package", "proxmox", "package name of the generated file")
+	include := flag.String("include", "", "comma separated API path prefixes to generate, all when empty")
+	skipBadPatterns := flag.Bool("skip-bad-patterns", false, "skip patterns Go regexp can't compile instead of failing")
+	flag.Parse()
+
+	data, err := ioutil.ReadFile(*schemaFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tree, err := loadSchema(data)
+	if err != nil {
+		log.Fatalf("parse %s: %v", *schemaFile, err)
+	}
+
+	var prefixes []string
+	if len(*include) > 0 {
+		prefixes = strings.Split(*include, ",")
+	}
+
+	ops, err := collect(tree, prefixes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g := newGenerator(*skipBadPatterns)
+	src, err := g.generate(*pkg, ops)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(*outFile, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "apigen: %d operations written to %s\n", len(ops), *outFile)
+}
+
+// loadSchema decodes the schema array. apidoc.js wraps it in a JS statement
+// ("const apiSchema = [...];" followed by viewer code), so decoding starts at
+// the first '[' and stops after the array.
+func loadSchema(data []byte) ([]*schemaNode, error) {
+	start := bytes.IndexByte(data, '[')
+	if start < 0 {
+		return nil, errors.New("schema array not found")
+	}
+
+	var tree []*schemaNode
+	if err := json.NewDecoder(bytes.NewReader(data[start:])).Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+func included(path string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, p := range prefixes {
+		p = strings.TrimRight(strings.TrimSpace(p), "/")
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func collect(tree []*schemaNode, prefixes []string) ([]operation, error) {
+	var ops []operation
+	names := make(map[string]string)
+
+	var walk func(nodes []*schemaNode) error
+	walk = func(nodes []*schemaNode) error {
+		for _, n := range nodes {
+			for _, m := range methodOrder {
+				ep, ok := n.Info[m]
+				if !ok || !included(n.Path, prefixes) {
+					continue
+				}
+				name := operationName(m, n.Path)
+				if prev, ok := names[name]; ok {
+					return fmt.Errorf("operation name %s of %s %s clashes with %s", name, m, n.Path, prev)
+				}
+				names[name] = m + " " + n.Path
+				ops = append(ops, operation{Path: n.Path, Method: m, Name: name, Endpoint: ep})
+			}
+			if err := walk(n.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(tree); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(ops, func(i, j int) bool { return ops[i].Name < ops[j].Name })
+
+	return ops, nil
+}
+
+// operationName builds the Go method name from the HTTP verb and the static
+// path segments; a trailing path parameter adds a "By<Param>" suffix.
+func operationName(method, path string) string {
+	name := methodVerbs[method]
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		if isPathParam(s) {
+			if i == len(segments)-1 {
+				name += "By" + goName(strings.Trim(s, "{}"))
+			}
+			continue
+		}
+		name += goName(s)
+	}
+	return name
+}
+
+func isPathParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func pathParams(path string) []string {
+	var params []string
+	for _, s := range strings.Split(strings.Trim(path, "/"), "/") {
+		if isPathParam(s) {
+			params = append(params, strings.Trim(s, "{}"))
+		}
+	}
+	return params
+}
+
+func goName(str string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range str {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if len(name) > 0 && unicode.IsDigit(rune(name[0])) {
+		name = "V" + name
+	}
+	return name
+}
+
+// indexedKey returns the base name of an indexed key like net[n].
+func indexedKey(key string) (string, bool) {
+	sm := indexedKeyRegexp.FindStringSubmatch(key)
+	if sm == nil {
+		return "", false
+	}
+	return sm[1], true
+}
+
+func fieldName(key string) string {
+	if base, ok := indexedKey(key); ok {
+		return goName(base)
+	}
+	return goName(key)
+}
+
+// goPattern converts a PVE (Perl) pattern to an anchored Go regexp. PVE
+// matches parameters against m/^$pattern$/, and stringified qr// patterns
+// carry a "(?^flags:" prefix that Go doesn't know.
+func goPattern(pattern string) (string, error) {
+	p := perlFlagsRegexp.ReplaceAllString(pattern, "(?$1:")
+	p = strings.Replace(p, `\Z`, `\z`, -1)
+	p = "^(?:" + p + ")$"
+
+	if _, err := regexp.Compile(p); err != nil {
+		return "", err
+	}
+
+	return p, nil
+}
+
+func (s *schema) optional() bool {
+	return s.Optional.String() == "1"
+}
+
+// format returns the string format name, or the sorted keys when the format
+// describes a property string.
+func (s *schema) format() (string, []string) {
+	if len(s.Format) == 0 {
+		return "", nil
+	}
+
+	var name string
+	if err := json.Unmarshal(s.Format, &name); err == nil {
+		return name, nil
+	}
+
+	var props map[string]*schema
+	if err := json.Unmarshal(s.Format, &props); err == nil {
+		return "", sortedKeys(props)
+	}
+
+	return "", nil
+}
+
+func sortedKeys(m map[string]*schema) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func plainGoType(s *schema) string {
+	switch s.Type {
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+func paramGoType(s *schema, optional bool, indexed bool) string {
+	t := plainGoType(s)
+	if indexed {
+		return "map[int]" + t
+	}
+	if optional {
+		return "*" + t
+	}
+	return t
+}
+
+func responseGoType(s *schema) string {
+	switch s.Type {
+	case "integer":
+		return "PVEInt"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "PVEBool"
+	case "string":
+		return "string"
+	case "array":
+		return "[]interface{}"
+	case "object":
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+}
+
+type writer struct {
+	bytes.Buffer
+}
+
+func (w *writer) line(format string, args ...interface{}) {
+	fmt.Fprintf(&w.Buffer, format, args...)
+	w.WriteByte('\n')
+}
+
+type generator struct {
+	skipBadPatterns bool
+	patternVars     map[string]string
+	patternOrder    []string
+}
+
+func newGenerator(skipBadPatterns bool) *generator {
+	return &generator{skipBadPatterns: skipBadPatterns, patternVars: make(map[string]string)}
+}
+
+// patternVar returns the package-level variable holding the compiled pattern.
+// Identical patterns share one variable named after their first user.
+func (g *generator) patternVar(name string, pattern string) (string, error) {
+	p, err := goPattern(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	if v, ok := g.patternVars[p]; ok {
+		return v, nil
+	}
+
+	v := strings.ToLower(name[:1]) + name[1:] + "Regexp"
+	g.patternVars[p] = v
+	g.patternOrder = append(g.patternOrder, p)
+
+	return v, nil
+}
+
+func (g *generator) generate(pkg string, ops []operation) ([]byte, error) {
+	body := &writer{}
+	for _, op := range ops {
+		if err := g.generateOperation(body, op); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(g.patternOrder) > 0 {
+		body.line("")
+		body.line("var (")
+		for _, p := range g.patternOrder {
+			body.line("\t%s = regexp.MustCompile(%q)", g.patternVars[p], p)
+		}
+		body.line(")")
+	}
+
+	w := &writer{}
+	w.line("// Code generated by apigen from apidoc.json. DO NOT EDIT.")
+	w.line("")
+	w.line("package %s", pkg)
+	w.line("")
+	w.line("import (")
+	for _, imp := range []string{"errors", "fmt", "net/url", "regexp", "strconv"} {
+		name := imp[strings.LastIndex(imp, "/")+1:]
+		if bytes.Contains(body.Bytes(), []byte(name+".")) {
+			w.line("\t%q", imp)
+		}
+	}
+	w.line(")")
+	w.Write(body.Bytes())
+
+	src, err := format.Source(w.Bytes())
+	if err != nil {
+		return w.Bytes(), errors.New("format generated source: " + err.Error())
+	}
+
+	return src, nil
+}
+
+func (g *generator) generateOperation(w *writer, op operation) error {
+	ep := op.Endpoint
+	paramsType := op.Name + "Params"
+	responseType := op.Name + "Response"
+
+	props := map[string]*schema{}
+	if ep.Parameters != nil && ep.Parameters.Properties != nil {
+		for k, v := range ep.Parameters.Properties {
+			props[k] = v
+		}
+	}
+
+	inPath := make(map[string]bool)
+	for _, p := range pathParams(op.Path) {
+		inPath[p] = true
+		if _, ok := props[p]; !ok {
+			props[p] = &schema{Type: "string"}
+		}
+	}
+
+	keys := sortedKeys(props)
+	hasParams := len(keys) > 0
+
+	if hasParams {
+		fields := make(map[string]string)
+
+		w.line("")
+		w.line("type %s struct {", paramsType)
+		for _, k := range keys {
+			p := props[k]
+			name := fieldName(k)
+			if prev, ok := fields[name]; ok {
+				return fmt.Errorf("%s: field %s of %s clashes with %s", paramsType, name, k, prev)
+			}
+			fields[name] = k
+
+			_, indexed := indexedKey(k)
+			optional := p.optional() && !inPath[k]
+			if len(p.Description) > 0 {
+				w.line("\t// %s", oneLine(p.Description))
+			}
+			if fmtName, fmtKeys := p.format(); len(fmtKeys) > 0 {
+				w.line("\t// Property string with keys: %s.", strings.Join(fmtKeys, ", "))
+			} else if len(fmtName) > 0 && p.Type != "integer" {
+				w.line("\t// Format: %s.", fmtName)
+			}
+			if indexed {
+				base, _ := indexedKey(k)
+				w.line("\t// Indexed by N, sent as %s<N>.", base)
+				w.line("\t%s %s `json:\"-\"`", name, paramGoType(p, optional, true))
+			} else {
+				w.line("\t%s %s `json:%q`", name, paramGoType(p, optional, false), k+omitEmpty(optional))
+			}
+		}
+		w.line("}")
+
+		for _, k := range keys {
+			p := props[k]
+			if len(p.Enum) == 0 {
+				continue
+			}
+			consts := make(map[string]bool)
+			w.line("")
+			w.line("const (")
+			for _, e := range p.Enum {
+				name := paramsType + fieldName(k) + goName(e)
+				for i := 2; consts[name]; i++ {
+					name = paramsType + fieldName(k) + goName(e) + strconv.Itoa(i)
+				}
+				consts[name] = true
+				w.line("\t%s = %q", name, e)
+			}
+			w.line(")")
+		}
+
+		if err := g.generateValidate(w, paramsType, keys, props, inPath); err != nil {
+			return err
+		}
+		generateValues(w, paramsType, keys, props, inPath)
+	}
+
+	returns := ep.Returns
+	if returns == nil {
+		returns = &schema{Type: "null"}
+	}
+
+	var resultType, zero string
+	switch {
+	case returns.Type == "object" && len(returns.Properties) > 0:
+		if err := generateResponse(w, responseType, returns.Properties); err != nil {
+			return err
+		}
+		resultType, zero = "*"+responseType, "nil"
+	case returns.Type == "array" && returns.Items != nil && len(returns.Items.Properties) > 0:
+		if err := generateResponse(w, responseType, returns.Items.Properties); err != nil {
+			return err
+		}
+		resultType, zero = "[]"+responseType, "nil"
+	case returns.Type == "null" || returns.Type == "":
+	default:
+		resultType = responseGoType(returns)
+		switch resultType {
+		case "PVEInt":
+			resultType, zero = "int64", "0"
+		case "PVEBool":
+			resultType, zero = "bool", "false"
+		case "float64":
+			zero = "0"
+		case "string":
+			zero = "\"\""
+		default:
+			zero = "nil"
+		}
+	}
+
+	w.line("")
+	if len(ep.Description) > 0 {
+		w.line("// %s calls %s %s. %s", op.Name, op.Method, op.Path, oneLine(ep.Description))
+	} else {
+		w.line("// %s calls %s %s.", op.Name, op.Method, op.Path)
+	}
+
+	args := ""
+	if hasParams {
+		args = "params *" + paramsType
+	}
+	if resultType == "" {
+		w.line("func (api *API) %s(%s) error {", op.Name, args)
+	} else {
+		w.line("func (api *API) %s(%s) (%s, error) {", op.Name, args, resultType)
+	}
+
+	ret := func(v string) string {
+		if resultType == "" {
+			return v
+		}
+		return zero + ", " + v
+	}
+
+	data := "nil"
+	if hasParams {
+		w.line("\tif params == nil {")
+		w.line("\t\tparams = &%s{}", paramsType)
+		w.line("\t}")
+		w.line("")
+		w.line("\terr := params.Validate()")
+		w.line("\tif err != nil {")
+		w.line("\t\treturn %s", ret("err"))
+		w.line("\t}")
+		w.line("")
+		data = "params.values()"
+	}
+
+	var parts []string
+	static := ""
+	for i, s := range strings.Split(strings.Trim(op.Path, "/"), "/") {
+		if i > 0 {
+			static += "/"
+		}
+		if isPathParam(s) {
+			if len(static) > 0 {
+				parts = append(parts, strconv.Quote(static))
+				static = ""
+			}
+			parts = append(parts, "url.PathEscape("+pathParamValue(strings.Trim(s, "{}"), props)+")")
+		} else {
+			static += s
+		}
+	}
+	if len(static) > 0 {
+		parts = append(parts, strconv.Quote(static))
+	}
+	w.line("\ttarget := %s", strings.Join(parts, " + "))
+	w.line("")
+
+	switch {
+	case resultType == "":
+		w.line("\tvar result interface{}")
+	case resultType == "int64":
+		w.line("\tvar result PVEInt")
+	case resultType == "bool":
+		w.line("\tvar result PVEBool")
+	case strings.HasPrefix(resultType, "*"):
+		w.line("\tvar result %s", responseType)
+	default:
+		w.line("\tvar result %s", resultType)
+	}
+	w.line("")
+	w.line("\thttpCode, err := api.px.APICall2(%q, target, %s, &result, nil)", op.Method, data)
+	w.line("\tif err != nil {")
+	w.line("\t\treturn %s", ret("err"))
+	w.line("\t}")
+	w.line("\tif httpCode != 200 {")
+	w.line("\t\treturn %s", ret("errors.New(fmt.Sprintf(\"HTTP Request return error: %d\", httpCode))"))
+	w.line("\t}")
+	w.line("")
+
+	switch {
+	case resultType == "":
+		w.line("\treturn nil")
+	case resultType == "int64":
+		w.line("\treturn int64(result), nil")
+	case resultType == "bool":
+		w.line("\treturn bool(result), nil")
+	case strings.HasPrefix(resultType, "*"):
+		w.line("\treturn &result, nil")
+	default:
+		w.line("\treturn result, nil")
+	}
+	w.line("}")
+
+	return nil
+}
+
+func pathParamValue(name string, props map[string]*schema) string {
+	switch props[name].Type {
+	case "integer":
+		return "strconv.FormatInt(params." + goName(name) + ", 10)"
+	default:
+		return "params." + goName(name)
+	}
+}
+
+func omitEmpty(optional bool) string {
+	if optional {
+		return ",omitempty"
+	}
+	return ""
+}
+
+func oneLine(str string) string {
+	return strings.Join(strings.Fields(str), " ")
+}
+
+// valueChecks returns the checks of value (a Go expression of the plain field
+// type) reporting errors under key (a Go string expression).
+func (g *generator) valueChecks(varName string, s *schema, value string, key string, required bool) ([]string, error) {
+	var checks []string
+
+	switch s.Type {
+	case "integer", "number":
+		if s.Minimum != nil {
+			min := strconv.FormatFloat(*s.Minimum, 'f', -1, 64)
+			checks = append(checks, fmt.Sprintf("if %s < %s {\n\terrs.add(%s, \"wrong value. It shuld be %s-N\")\n}", value, min, key, min))
+		}
+		if s.Maximum != nil {
+			max := strconv.FormatFloat(*s.Maximum, 'f', -1, 64)
+			checks = append(checks, fmt.Sprintf("if %s > %s {\n\terrs.add(%s, \"wrong value. It shuld be less or equal %s\")\n}", value, max, key, max))
+		}
+	case "boolean":
+	default:
+		if required {
+			checks = append(checks, fmt.Sprintf("if len(%s) == 0 {\n\terrs.add(%s, \"could not be zero\")\n}", value, key))
+		}
+		if len(s.Enum) > 0 {
+			var cases []string
+			for _, e := range s.Enum {
+				cases = append(cases, strconv.Quote(e))
+			}
+			checks = append(checks, fmt.Sprintf("switch %s {\ncase %s:\ndefault:\n\terrs.add(%s, \"wrong value. Posible values is: [%s]\")\n}",
+				value, strings.Join(cases, ", "), key, strings.Join(s.Enum, "|")))
+		}
+		if s.MaxLength != nil {
+			checks = append(checks, fmt.Sprintf("if len(%s) > %d {\n\terrs.add(%s, \"too long. It shuld be 0-%d characters\")\n}", value, *s.MaxLength, key, *s.MaxLength))
+		}
+		if len(s.Pattern) > 0 {
+			v, err := g.patternVar(varName, s.Pattern)
+			if err != nil && !g.skipBadPatterns {
+				return nil, fmt.Errorf("%s: pattern %q: %v", varName, s.Pattern, err)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "apigen: %s: pattern %q skipped: %v\n", varName, s.Pattern, err)
+			} else {
+				checks = append(checks, fmt.Sprintf("if len(%s) > 0 && !%s.MatchString(%s) {\n\terrs.add(%s, \"wrong value: %%s\", %s)\n}", value, v, value, key, value))
+			}
+		}
+		if name, _ := s.format(); len(name) > 0 && len(s.Pattern) == 0 {
+			list := false
+			if _, ok := formatChecks[name]; !ok && strings.HasSuffix(name, "-list") {
+				name, list = strings.TrimSuffix(name, "-list"), true
+			}
+			if check, ok := formatChecks[name]; ok {
+				if list {
+					checks = append(checks, fmt.Sprintf("for _, v := range splitList(%s) {\n\tif !%s {\n\t\terrs.add(%s, \"wrong value: %%s\", v)\n\t}\n}", value, strings.Replace(check, "{v}", "v", -1), key))
+				} else {
+					checks = append(checks, fmt.Sprintf("if len(%s) > 0 && !%s {\n\terrs.add(%s, \"wrong value: %%s\", %s)\n}", value, strings.Replace(check, "{v}", value, -1), key, value))
+				}
+			}
+		}
+	}
+
+	return checks, nil
+}
+
+func indent(str string, prefix string) string {
+	return prefix + strings.Replace(str, "\n", "\n"+prefix, -1)
+}
+
+func (g *generator) generateValidate(w *writer, paramsType string, keys []string, props map[string]*schema, inPath map[string]bool) error {
+	w.line("")
+	w.line("func (p *%s) Validate() error {", paramsType)
+	w.line("\tvar errs ValidationErrors")
+
+	for _, k := range keys {
+		s := props[k]
+		field := "p." + fieldName(k)
+		varName := paramsType + fieldName(k)
+
+		if base, indexed := indexedKey(k); indexed {
+			checks, err := g.valueChecks(varName, s, "v", "key", false)
+			if err != nil {
+				return err
+			}
+			if len(checks) > 0 {
+				w.line("\tfor i, v := range %s {", field)
+			} else {
+				w.line("\tfor i := range %s {", field)
+			}
+			w.line("\t\tkey := fmt.Sprintf(\"%s%%d\", i)", base)
+			w.line("\t\tif i < 0 {")
+			w.line("\t\t\terrs.add(key, \"wrong index\")")
+			w.line("\t\t}")
+			for _, c := range checks {
+				w.line("%s", indent(c, "\t\t"))
+			}
+			w.line("\t}")
+			continue
+		}
+
+		optional := s.optional() && !inPath[k]
+		value := field
+		if optional {
+			value = "*" + field
+		}
+
+		checks, err := g.valueChecks(varName, s, value, strconv.Quote(k), !optional)
+		if err != nil {
+			return err
+		}
+
+		if len(checks) == 0 {
+			continue
+		}
+
+		if optional {
+			w.line("\tif %s != nil {", field)
+			for _, c := range checks {
+				w.line("%s", indent(c, "\t\t"))
+			}
+			w.line("\t}")
+		} else {
+			for _, c := range checks {
+				w.line("%s", indent(c, "\t"))
+			}
+		}
+	}
+
+	w.line("\treturn errs.orNil()")
+	w.line("}")
+
+	return nil
+}
+
+func encodeValue(s *schema, value string) string {
+	switch s.Type {
+	case "integer":
+		return fmt.Sprintf("strconv.FormatInt(%s, 10)", value)
+	case "number":
+		return fmt.Sprintf("strconv.FormatFloat(%s, 'f', -1, 64)", value)
+	default:
+		return value
+	}
+}
+
+func generateValues(w *writer, paramsType string, keys []string, props map[string]*schema, inPath map[string]bool) {
+	w.line("")
+	w.line("func (p *%s) values() url.Values {", paramsType)
+	w.line("\tdata := make(url.Values)")
+
+	for _, k := range keys {
+		if inPath[k] {
+			continue
+		}
+		s := props[k]
+		field := "p." + fieldName(k)
+
+		addValue := func(indent string, key string, value string) {
+			if s.Type == "boolean" {
+				w.line("%sif %s {", indent, value)
+				w.line("%s\tdata.Add(%s, \"1\")", indent, key)
+				w.line("%s} else {", indent)
+				w.line("%s\tdata.Add(%s, \"0\")", indent, key)
+				w.line("%s}", indent)
+			} else {
+				w.line("%sdata.Add(%s, %s)", indent, key, encodeValue(s, value))
+			}
+		}
+
+		if base, indexed := indexedKey(k); indexed {
+			w.line("\tfor i, v := range %s {", field)
+			addValue("\t\t", strconv.Quote(base)+"+strconv.Itoa(i)", "v")
+			w.line("\t}")
+			continue
+		}
+
+		if s.optional() {
+			w.line("\tif %s != nil {", field)
+			addValue("\t\t", strconv.Quote(k), "*"+field)
+			w.line("\t}")
+		} else {
+			addValue("\t", strconv.Quote(k), field)
+		}
+	}
+
+	w.line("\treturn data")
+	w.line("}")
+}
+
+func generateResponse(w *writer, responseType string, props map[string]*schema) error {
+	fields := make(map[string]string)
+
+	w.line("")
+	w.line("type %s struct {", responseType)
+	for _, k := range sortedKeys(props) {
+		p := props[k]
+		if base, indexed := indexedKey(k); indexed {
+			w.line("\t// %s0, %s1, ... are not decoded.", base, base)
+			continue
+		}
+		name := goName(k)
+		if prev, ok := fields[name]; ok {
+			return fmt.Errorf("%s: field %s of %s clashes with %s", responseType, name, k, prev)
+		}
+		fields[name] = k
+		if len(p.Description) > 0 {
+			w.line("\t// %s", oneLine(p.Description))
+		}
+		w.line("\t%s %s `json:%q`", name, responseGoType(p), k+omitEmpty(p.optional()))
+	}
+	w.line("}")
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const testSchema = `const apiSchema = [
+  {
+    "path": "/nodes/{node}/qemu",
+    "text": "qemu",
+    "info": {
+      "POST": {
+        "method": "POST",
+        "name": "create_vm",
+        "description": "Create or restore a virtual machine.",
+        "parameters": {
+          "properties": {
+            "node": {"type": "string", "format": "pve-node"},
+            "vmid": {"type": "integer", "minimum": 100, "maximum": 999999999},
+            "name": {"type": "string", "format": "dns-name", "optional": 1},
+            "net[n]": {"type": "string", "optional": 1, "format": {"model": {"type": "string"}, "bridge": {"type": "string", "optional": 1}}},
+            "tags": {"type": "string", "format": "pve-tag-list", "optional": 1},
+            "hookscript": {"type": "string", "pattern": "(?^i:[a-z0-9_\\-]+:snippets/\\S+)", "optional": 1}
+          }
+        },
+        "returns": {"type": "string"}
+      }
+    }
+  }
+];
+let method2cmd = { "GET": "get" };`
+
+func TestLoadSchema(t *testing.T) {
+	tree, err := loadSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != 1 || tree[0].Path != "/nodes/{node}/qemu" {
+		t.Errorf("loadSchema() = %v", tree)
+	}
+}
+
+func TestGoPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		match   string
+		noMatch string
+		wantErr bool
+	}{
+		{name: "Unanchored pattern", pattern: "[a-z]+", match: "abc", noMatch: "abc1"},
+		{name: "Perl qr flags", pattern: "(?^i:[a-z]+)", match: "ABC", noMatch: "A-C"},
+		{name: "Perl qr without flags", pattern: "(?^:\\d+)", match: "12", noMatch: "1a"},
+		{name: "Perl only lookbehind", pattern: "(?<!a)b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := goPattern(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("goPattern() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			re := regexp.MustCompile(got)
+			if !re.MatchString(tt.match) || re.MatchString(tt.noMatch) {
+				t.Errorf("goPattern() = %s, match %s, no match %s", got, tt.match, tt.noMatch)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tree, err := loadSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, err := collect(tree, []string{"/nodes/{node}/qemu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := newGenerator(false).generate("proxmox", ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(src)
+
+	for _, want := range []string{
+		"Net map[int]string `json:\"-\"`",
+		"data.Add(\"net\"+strconv.Itoa(i), v)",
+		"key := fmt.Sprintf(\"net%d\", i)",
+		"createNodesQemuParamsHookscriptRegexp = regexp.MustCompile(",
+		"!createNodesQemuParamsHookscriptRegexp.MatchString(*p.Hookscript)",
+		"!isDNSName(*p.Name)",
+		"for _, v := range splitList(*p.Tags)",
+		"// Property string with keys: bridge, model.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("generate() output has no %q", want)
+		}
+	}
+	if strings.Contains(out, "net[n]") || strings.Contains(out, "NetN") {
+		t.Errorf("generate() output has unexpanded indexed key")
+	}
+	if strings.Contains(out, "MustCompile(") && strings.Count(out, "MustCompile(") != 1 {
+		t.Errorf("generate() compiles patterns outside package variables")
+	}
+}
+
+func TestGenerate_BadPattern(t *testing.T) {
+	schema := strings.Replace(testSchema, "(?^i:[a-z0-9_\\\\-]+:snippets/\\\\S+)", "(?<!x)y", 1)
+	tree, err := loadSchema([]byte(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, err := collect(tree, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newGenerator(false).generate("proxmox", ops); err == nil {
+		t.Errorf("generate() error = nil, want pattern error")
+	}
+
+	src, err := newGenerator(true).generate("proxmox", ops)
+	if err != nil {
+		t.Errorf("generate() with skip-bad-patterns error = %v", err)
+	}
+	if strings.Contains(string(src), "HookscriptRegexp") {
+		t.Errorf("generate() with skip-bad-patterns kept the pattern")
+	}
+}
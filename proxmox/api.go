@@ -0,0 +1,37 @@
+package proxmox
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//go:generate go run ../apigen/main.go -schema ../apigen/apidoc.json -include /access/users,/cluster/nextid,/pools -out zz_generated_api.go
+
+var storageIdRegexp = regexp.MustCompile("^[a-z][a-z0-9\\-_.]*[a-z0-9]$")
+
+type API struct {
+	px *Proxmox
+}
+
+func (px *Proxmox) API() *API {
+	return &API{px: px}
+}
+
+// splitList splits PVE "-list" format values the way PVE::Tools::split_list does.
+func splitList(str string) []string {
+	return strings.FieldsFunc(str, func(r rune) bool {
+		return r == ',' || r == ';' || r == 0 || unicode.IsSpace(r)
+	})
+}
+
+func isCIDR(str string, v6 bool) bool {
+	_, ok := parseCIDR(str, v6)
+	return ok
+}
+
+func isVmIdString(str string) bool {
+	vmid, err := strconv.ParseInt(str, 10, 64)
+	return err == nil && vmid >= VMID_MIN && vmid <= VMID_MAX
+}
@@ -0,0 +1,240 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	VMID_MIN = 100
+	VMID_MAX = 999999999
+
+	VMID_ALLOCATOR_RETRIES = 5
+	VMID_ALLOCATOR_TASK_TIMEOUT = 600
+)
+
+type ClusterResource struct {
+	Id string			`json:"id"`
+	Type string			`json:"type"`
+	Node string			`json:"node"`
+	Name string			`json:"name"`
+	Status string		`json:"status"`
+	Storage string		`json:"storage"`
+	Pool string			`json:"pool"`
+	Template PVEBool	`json:"template"`
+	VmId PVEInt			`json:"vmid"`
+}
+
+// VmIdAllocator hands out free VmIds from [Min, Max]. Reservations are kept in
+// process memory only: allocators in other processes or on other hosts do not see
+// them, so two of them can still pick the same id. CreateLxc covers that race by
+// retrying on "already exists" failures.
+type VmIdAllocator struct {
+	Min int64
+	Max int64
+	Retries int
+	TaskTimeout int
+
+	px *Proxmox
+	mu sync.Mutex
+	reserved map[int64]bool
+}
+
+func (px *Proxmox) NextID() (int64, error) {
+	return px.API().GetClusterNextid(nil)
+}
+
+func (px *Proxmox) IsFreeID(vmid int64) (bool, error) {
+	if vmid < VMID_MIN || vmid > VMID_MAX {
+		return false, errors.New(fmt.Sprintf("VmId has wrong value. It shuld be %d-%d", VMID_MIN, VMID_MAX))
+	}
+
+	data := make(url.Values)
+	data.Add("vmid", strconv.Itoa(int(vmid)))
+
+	var nextID PVEInt
+
+	httpCode, err := px.APICall2("GET", "cluster/nextid", data, &nextID, nil)
+	if err != nil {
+		// PVE answers 400 "VM <vmid> already exists" for a taken id.
+		if ae, ok := err.(*APIError); ok && ae.Code == 400 && IsAlreadyExists(ae) {
+			return false, nil
+		}
+		return false, err
+	}
+	if httpCode != 200 {
+		return false, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return true, nil
+}
+
+func (px *Proxmox) GetClusterResources(resourceType string) ([]ClusterResource, error) {
+	data := make(url.Values)
+	if len(resourceType) > 0 {
+		data.Add("type", resourceType)
+	}
+
+	var resources []ClusterResource
+
+	httpCode, err := px.APICall2("GET", "cluster/resources", data, &resources, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return resources, nil
+}
+
+func (px *Proxmox) NewVmIdAllocator(min int64, max int64) (*VmIdAllocator, error) {
+	if min < VMID_MIN || max > VMID_MAX || min > max {
+		return nil, errors.New(fmt.Sprintf("VmId range has wrong value. It shuld be within %d-%d", VMID_MIN, VMID_MAX))
+	}
+
+	return &VmIdAllocator{
+		Min: min,
+		Max: max,
+		Retries: VMID_ALLOCATOR_RETRIES,
+		TaskTimeout: VMID_ALLOCATOR_TASK_TIMEOUT,
+		px: px,
+		reserved: make(map[int64]bool),
+	}, nil
+}
+
+func (a *VmIdAllocator) Reserve() (int64, error) {
+	resources, err := a.px.GetClusterResources("vm")
+	if err != nil {
+		return 0, err
+	}
+
+	used := make(map[int64]bool)
+	for _, r := range resources {
+		used[int64(r.VmId)] = true
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Start at a random offset so concurrent allocators rarely probe the same ids.
+	size := a.Max - a.Min + 1
+	offset := rand.Int63n(size)
+
+	for i := int64(0); i < size; i++ {
+		vmid := a.Min + (offset + i) % size
+		if used[vmid] || a.reserved[vmid] {
+			continue
+		}
+
+		free, err := a.px.IsFreeID(vmid)
+		if err != nil {
+			return 0, err
+		}
+		if free {
+			a.reserved[vmid] = true
+			return vmid, nil
+		}
+	}
+
+	return 0, errors.New(fmt.Sprintf("no free VmId in range %d-%d", a.Min, a.Max))
+}
+
+func (a *VmIdAllocator) Release(vmid int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.reserved, vmid)
+}
+
+func (a *VmIdAllocator) IsFree(vmid int64) (bool, error) {
+	if vmid < a.Min || vmid > a.Max {
+		return false, nil
+	}
+
+	a.mu.Lock()
+	reserved := a.reserved[vmid]
+	a.mu.Unlock()
+
+	if reserved {
+		return false, nil
+	}
+
+	return a.px.IsFreeID(vmid)
+}
+
+// CreateLxc creates a container on a reserved VmId and waits for the create task
+// to finish, moving on to another id when the chosen one turns out to be taken.
+func (a *VmIdAllocator) CreateLxc(n *Node, lxcParams LxcConfig) (*Task, error) {
+	retries := a.Retries
+	if retries < 1 {
+		retries = 1
+	}
+
+	var err error
+	for i := 0; i < retries; i++ {
+		var vmid int64
+		vmid, err = a.Reserve()
+		if err != nil {
+			return nil, err
+		}
+
+		lxcParams.VmId = vmid
+
+		var taskID *TaskID
+		taskID, err = n.CreateLxc(lxcParams)
+		if err != nil {
+			if IsAlreadyExists(err) {
+				continue
+			}
+			a.Release(vmid)
+			return nil, err
+		}
+
+		task := n.GetTask(*taskID)
+
+		var finished bool
+		finished, err = waitCreateTask(task, a.TaskTimeout)
+		if err == nil {
+			return task, nil
+		}
+
+		// The task may still create the guest, so the VmId stays reserved.
+		if !finished {
+			return task, err
+		}
+
+		if !IsAlreadyExists(err) {
+			a.Release(vmid)
+			return task, err
+		}
+	}
+
+	return nil, err
+}
+
+// waitCreateTask waits for a create task to finish and reports whether it did.
+// PVE checks the VmId once before the worker starts and again when the worker
+// writes the config, so a collision can surface either as an API error or as a
+// failed task.
+func waitCreateTask(task *Task, timeout int) (bool, error) {
+	_, taskStatus, err := task.WaitForStatus("stopped", timeout)
+	if err != nil {
+		return false, err
+	}
+
+	if taskStatus.ExitStatus == "OK" {
+		return true, nil
+	}
+
+	if strings.Contains(taskStatus.ExitStatus, "already exists") {
+		return true, &APIError{Code: 500, Message: taskStatus.ExitStatus}
+	}
+
+	return true, errors.New(fmt.Sprintf("task %s failed: %s", task.UPid, taskStatus.ExitStatus))
+}
@@ -0,0 +1,74 @@
+package proxmox
+
+import (
+	"regexp"
+)
+
+const (
+	GUEST_TYPE_LXC = "lxc"
+	GUEST_TYPE_QEMU = "qemu"
+
+	GUEST_STATUS_RUNNING = "running"
+	GUEST_STATUS_STOPPED = "stopped"
+
+	SNAPSHOT_CURRENT = "current"
+)
+
+var snapnameRegexp = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9_\\-]{1,39}$")
+
+type Guest interface {
+	GetVmId() int64
+	GetName() string
+	GetType() string
+	GetNode() *Node
+	GetTags() []string
+	SetTags(tags []string) error
+	GetGuestStatus() (string, error)
+	GetConfigDigest() (string, error)
+
+	StartGuest(skiplock bool) (*Task, error)
+	StopGuest(skiplock bool) (*Task, error)
+	ShutdownGuest(forceStop bool, timeout int) (*Task, error)
+	Reboot(timeout int) (*Task, error)
+
+	CreateSnapshot(snapname string, description string) (*Task, error)
+	GetSnapshots() ([]GuestSnapshot, error)
+	GetSnapshotTree() ([]*GuestSnapshot, error)
+	RollbackSnapshot(snapname string, start bool) (*Task, error)
+	DeleteSnapshot(snapname string, force bool) (*Task, error)
+
+	MigrateTo(targetNode string, online bool) (*Task, error)
+}
+
+var _ Guest = (*Lxc)(nil)
+var _ Guest = (*Qemu)(nil)
+
+type GuestSnapshot struct {
+	Name string					`json:"name"`
+	Description string			`json:"description"`
+	SnapTime PVEInt				`json:"snaptime"`
+	VMState PVEBool				`json:"vmstate"`
+	Parent string				`json:"parent"`
+	Running PVEBool				`json:"running"`
+	Children []*GuestSnapshot	`json:"-"`
+}
+
+func buildSnapshotTree(snapshots []GuestSnapshot) []*GuestSnapshot {
+	byName := make(map[string]*GuestSnapshot)
+	for i := range snapshots {
+		snapshots[i].Children = nil
+		byName[snapshots[i].Name] = &snapshots[i]
+	}
+
+	var roots []*GuestSnapshot
+	for i := range snapshots {
+		s := &snapshots[i]
+		if p, ok := byName[s.Parent]; ok && len(s.Parent) > 0 {
+			p.Children = append(p.Children, s)
+		} else {
+			roots = append(roots, s)
+		}
+	}
+
+	return roots
+}
@@ -0,0 +1,105 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	GUEST_FEATURE_SNAPSHOT = "snapshot"
+	GUEST_FEATURE_CLONE = "clone"
+	GUEST_FEATURE_COPY = "copy"
+)
+
+// Storage types whose volumes support a feature regardless of the image format.
+// Only used to explain a negative hasFeature answer, PVE makes the decision.
+var guestFeatureStorageTypes = map[string][]string{
+	GUEST_FEATURE_SNAPSHOT: {"zfspool", "lvmthin", "rbd", "btrfs"},
+	GUEST_FEATURE_CLONE: {"zfspool", "lvmthin", "rbd", "btrfs"},
+	GUEST_FEATURE_COPY: {"dir", "nfs", "cifs", "glusterfs", "cephfs", "lvm", "lvmthin", "zfspool", "rbd", "btrfs"},
+}
+
+// File based storage types where qcow2 images also support snapshot and clone.
+var guestFeatureQcow2StorageTypes = []string{"dir", "nfs", "cifs", "glusterfs", "cephfs"}
+
+type GuestFeature struct {
+	HasFeature PVEBool	`json:"hasFeature"`
+	Nodes []string		`json:"nodes"`
+}
+
+func getGuestFeature(n *Node, target string, feature string, snapshot string) (*GuestFeature, error) {
+	if _, ok := guestFeatureStorageTypes[feature]; !ok {
+		return nil, errors.New(fmt.Sprintf("feature has wrong value. Posible values is: [%s|%s|%s]", GUEST_FEATURE_SNAPSHOT, GUEST_FEATURE_CLONE, GUEST_FEATURE_COPY))
+	}
+
+	data := make(url.Values)
+	data.Add("feature", feature)
+	if len(snapshot) > 0 { data.Add("snapname", snapshot) }
+
+	var guestFeature GuestFeature
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, data, &guestFeature, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return &guestFeature, nil
+}
+
+// guestFeatureBlockers lists the volumes (as "key=volume") whose storage can not provide the feature.
+func guestFeatureBlockers(n *Node, feature string, volumes map[string]string) ([]string, error) {
+	storageList, err := n.GetStorageList()
+	if err != nil {
+		return nil, err
+	}
+
+	storageTypes := make(map[string]string)
+	for _, s := range storageList {
+		storageTypes[s.Storage] = s.Type
+	}
+
+	var blockers []string
+	for key, volume := range volumes {
+		if len(volume) == 0 {
+			continue
+		}
+
+		if !volumeHasFeature(storageTypes, feature, volume) {
+			blockers = append(blockers, key + "=" + volume)
+		}
+	}
+
+	sort.Strings(blockers)
+
+	return blockers, nil
+}
+
+func volumeHasFeature(storageTypes map[string]string, feature string, volume string) bool {
+	parts := strings.SplitN(volume, ":", 2)
+	if len(parts) != 2 || strings.HasPrefix(volume, "/") {
+		return false
+	}
+
+	storageType := storageTypes[parts[0]]
+	for _, t := range guestFeatureStorageTypes[feature] {
+		if storageType == t {
+			return true
+		}
+	}
+
+	if strings.HasSuffix(parts[1], ".qcow2") {
+		for _, t := range guestFeatureQcow2StorageTypes {
+			if storageType == t {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -24,7 +25,10 @@ type LxcConfig struct {
 
 	MountPoints []MountPoint
 	Networks []NetworkConfig
+	Devices []DeviceConfig
 	Startup StartupConfig
+	Features LxcFeatures
+	Tags []string
 
 	BaseLxcConfig
 }
@@ -148,59 +152,305 @@ func (lxc *Lxc) WaitForStatus(status string, timeout int) (bool,*LxcStatus, erro
 	return false, lxcStatus, errors.New("timeout reached, status not get")
 }
 
+func (lxc *Lxc) GetConfig() (*LxcConfig, error) {
+	target := "nodes/" + lxc.parent.(*Node).Node + "/lxc/" + strconv.Itoa(int(lxc.VmId)) + "/config"
+
+	var lxcConfigReceiver LxcConfigReceiver
+
+	httpCode, err := lxc.parent.(*Node).parent.(*Proxmox).APICall2("GET",target, nil, &lxcConfigReceiver, nil)
+
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return lxcConfigReceiver.Parse(), nil
+}
+
+func (lxc *Lxc) getSnapshotConfig(snapname string) (*LxcConfig, error) {
+	target := "nodes/" + lxc.parent.(*Node).Node + "/lxc/" + strconv.Itoa(int(lxc.VmId)) + "/snapshot/" + url.PathEscape(snapname) + "/config"
+
+	var lxcConfigReceiver LxcConfigReceiver
+
+	httpCode, err := lxc.parent.(*Node).parent.(*Proxmox).APICall2("GET",target, nil, &lxcConfigReceiver, nil)
+
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return lxcConfigReceiver.Parse(), nil
+}
+
+func (lxc *Lxc) CheckFeature(feature string, snapshot string) (bool, error) {
+	target := "nodes/" + lxc.parent.(*Node).Node + "/lxc/" + strconv.Itoa(int(lxc.VmId)) + "/feature"
+
+	guestFeature, err := getGuestFeature(lxc.parent.(*Node), target, feature, snapshot)
+	if err != nil {
+		return false, err
+	}
+
+	return bool(guestFeature.HasFeature), nil
+}
+
+func (lxc *Lxc) requireFeature(feature string, snapshot string) error {
+	hasFeature, err := lxc.CheckFeature(feature, snapshot)
+	if err != nil {
+		return err
+	}
+	if hasFeature {
+		return nil
+	}
+
+	// PVE has the final word, the volume list only explains the refusal.
+	blockers, err := lxc.featureBlockers(feature, snapshot)
+	if err != nil || len(blockers) == 0 {
+		return errors.New(fmt.Sprintf("Lxc container VMID: %d does not support feature %s", lxc.VmId, feature))
+	}
+
+	return errors.New(fmt.Sprintf("Lxc container VMID: %d does not support feature %s, blocked by volumes: %s", lxc.VmId, feature, strings.Join(blockers, ", ")))
+}
+
+func (lxc *Lxc) featureBlockers(feature string, snapshot string) ([]string, error) {
+	var lxcConfig *LxcConfig
+	var err error
+	if len(snapshot) > 0 {
+		lxcConfig, err = lxc.getSnapshotConfig(snapshot)
+	} else {
+		lxcConfig, err = lxc.GetConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rootfs := MountPoint{}
+	rootfs.SetFromString(0, lxcConfig.RootFS)
+
+	volumes := map[string]string{"rootfs": rootfs.Volume}
+	for _, mp := range lxcConfig.MountPoints {
+		volumes["mp" + strconv.Itoa(mp.Index)] = mp.Volume
+	}
+
+	return guestFeatureBlockers(lxc.parent.(*Node), feature, volumes)
+}
+
+func (lxc *Lxc) Snapshot(snapname string, description string) (*Task, error) {
+	if len(snapname) == 0 {
+		return nil, errors.New("snapname could not be zero")
+	}
+
+	err := lxc.requireFeature(LXC_FEATURE_SNAPSHOT, "")
+	if err != nil {
+		return nil, err
+	}
+
+	target := "nodes/" + lxc.parent.(*Node).Node + "/lxc/" + strconv.Itoa(int(lxc.VmId)) + "/snapshot"
+
+	data := make(url.Values)
+	data.Add("snapname", snapname)
+	if len(description) > 0 {
+		data.Add("description", description)
+	}
+
+	return lxc.taskCall("POST", target, data)
+}
+
+func (lxc *Lxc) Clone(newid int64, hostname string, full bool, storage string, snapname string) (*Task, error) {
+	feature := LXC_FEATURE_CLONE
+	if full {
+		feature = LXC_FEATURE_COPY
+	}
+
+	err := lxc.requireFeature(feature, snapname)
+	if err != nil {
+		return nil, err
+	}
+
+	target := "nodes/" + lxc.parent.(*Node).Node + "/lxc/" + strconv.Itoa(int(lxc.VmId)) + "/clone"
+
+	data := make(url.Values)
+	data.Add("newid", strconv.Itoa(int(newid)))
+	if len(hostname) > 0 { data.Add("hostname", hostname) }
+	if full { data.Add("full", "1") }
+	if len(storage) > 0 { data.Add("storage", storage) }
+	if len(snapname) > 0 { data.Add("snapname", snapname) }
+
+	return lxc.taskCall("POST", target, data)
+}
+
 func (clp *LxcConfig) Validate() error {
+	var errs ValidationErrors
+
+	create := !clp.Restore
+
+	switch clp.Arch {
+	case LXC_ARCH_AMD64, LXC_ARCH_I386, LXC_ARCH_ARM64, LXC_ARCH_ARMHF, "":
+	default:
+		errs.add("arch", "wrong value. Posible values is: [%s|%s|%s|%s|empty]", LXC_ARCH_AMD64, LXC_ARCH_I386, LXC_ARCH_ARM64, LXC_ARCH_ARMHF)
+	}
+
+	switch clp.CMode {
+	case LXC_CMODE_CONSOLE, LXC_CMODE_SHELL, LXC_CMODE_TTY, "":
+	default:
+		errs.add("cmode", "wrong value. Posible values is: [%s|%s|%s|empty]", LXC_CMODE_CONSOLE, LXC_CMODE_SHELL, LXC_CMODE_TTY)
+	}
 
-	if clp.Arch != "" && clp.Arch != LXC_ARCH_AMD64 && clp.Arch != LXC_ARCH_I386 && !clp.Restore {
-		return errors.New(fmt.Sprintf("Arch has wrong value. Posible values is: %s or %s or empty", LXC_ARCH_I386, LXC_ARCH_AMD64))
+	if (create || clp.Cores != 0) && (clp.Cores < 1 || clp.Cores > 128) {
+		errs.add("cores", "wrong value. It shuld be 1-128")
 	}
 
-	if clp.CMode != "" && clp.CMode != LXC_CMODE_CONSOLE && clp.CMode != LXC_CMODE_SHELL && clp.CMode != LXC_CMODE_TTY && !clp.Restore {
-		return errors.New(fmt.Sprintf("CMode has wrong value. Posible values is: [%s|%s|%s|empty]",LXC_CMODE_CONSOLE,LXC_CMODE_SHELL,LXC_CMODE_TTY))
+	if clp.CpuLimit < 0 || clp.CpuLimit > 128 {
+		errs.add("cpulimit", "wrong value. It shuld be 0-128")
 	}
 
-	if (clp.Cores <1 || clp.Cores >128) && !clp.Restore {
-		return errors.New("cores has wrong value. it shuld be 1-128")
+	if clp.CpuUnits < 0 || clp.CpuUnits > 500000 {
+		errs.add("cpuunits", "wrong value. It shuld be 0-500000")
 	}
 
-	if (clp.CpuLimit <0 || clp.CpuLimit >128) && !clp.Restore {
-		return errors.New("CpuLimit has wrong value. It shuld be 0-128")
+	if len(clp.Description) > 8192 {
+		errs.add("description", "too long. It shuld be 0-8192 bytes")
 	}
 
-	if (clp.CpuUnits <0 || clp.CpuUnits > 500000) && !clp.Restore {
-		return errors.New("CpuLimit has wrong value. It shuld be 0-500000")
+	if len(clp.Hostname) > 0 && !isDNSName(clp.Hostname) {
+		errs.add("hostname", "wrong value. It shuld be a valid DNS name")
 	}
 
 	switch clp.Lock {
-	case LXC_LOCK_BACKUP :
-	case LXC_LOCK_MIGRATE:
-	case LXC_LOCK_ROLBACK:
-	case LXC_LOCK_SNAPSHOT:
-	case "":
+	case LXC_LOCK_BACKUP, LXC_LOCK_CREATE, LXC_LOCK_DESTROYED, LXC_LOCK_DISK, LXC_LOCK_FSTRIM, LXC_LOCK_MIGRATE,
+		LXC_LOCK_MOUNTED, LXC_LOCK_ROLBACK, LXC_LOCK_SNAPSHOT, LXC_LOCK_SNAPSHOT_DELETE, "":
 	default:
-		return errors.New(fmt.Sprintf("CMode has wrong value. Posible values is: [%s|%s|%s|%s|empty]",LXC_LOCK_SNAPSHOT,LXC_LOCK_ROLBACK,LXC_LOCK_MIGRATE,LXC_LOCK_BACKUP))
+		errs.add("lock", "wrong value. Posible values is: [%s]", strings.Join([]string{LXC_LOCK_BACKUP, LXC_LOCK_CREATE, LXC_LOCK_DESTROYED,
+			LXC_LOCK_DISK, LXC_LOCK_FSTRIM, LXC_LOCK_MIGRATE, LXC_LOCK_MOUNTED, LXC_LOCK_ROLBACK, LXC_LOCK_SNAPSHOT, LXC_LOCK_SNAPSHOT_DELETE, "empty"}, "|"))
+	}
+
+	if (create || clp.Memory != 0) && clp.Memory < 16 {
+		errs.add("memory", "wrong value. It shuld be 16-N")
 	}
 
-	if clp.Memory < 16 && !clp.Restore {
-		return errors.New("memory has wrong value. It shuld be 16-N")
+	for _, ns := range strings.Fields(strings.Replace(clp.NameServer, ",", " ", -1)) {
+		if !isIPv4(ns) && !isIPv6(ns) {
+			errs.add("nameserver", "wrong IP address: %s", ns)
+		}
 	}
 
 	if len(clp.OSTemplate) == 0 {
-		return errors.New("OSTemplate could not be zero")
+		errs.add("ostemplate", "could not be zero")
 	}
 
-	if len(clp.Password) < 6 && !clp.Restore {
-		return errors.New("password length could not be less than 6")
+	switch clp.OSType {
+	case LXC_OSTYPE_DEBIAN, LXC_OSTYPE_DEVUAN, LXC_OSTYPE_UBUNTU, LXC_OSTYPE_CENTOS, LXC_OSTYPE_FEDORA, LXC_OSTYPE_OPENSUSE,
+		LXC_OSTYPE_ARCHLINUX, LXC_OSTYPE_ALPINE, LXC_OSTYPE_GENTOO, LXC_OSTYPE_NIXOS, LXC_OSTYPE_UNMANAGED, "":
+	default:
+		errs.add("ostype", "wrong value: %s", clp.OSType)
 	}
 
-	if len(clp.RootFS) == 0 && !clp.Restore {
-		return errors.New("RootFS could not be zero")
+	if (create || len(clp.Password) > 0) && len(clp.Password) < 6 {
+		errs.add("password", "length could not be less than 6")
 	}
 
-	if clp.VmId < 0 && !clp.Restore {
-		return errors.New("VmId has wrong value")
+	if len(clp.Pool) > 0 && !poolNameRegexp.MatchString(clp.Pool) {
+		errs.add("pool", "wrong value: %s", clp.Pool)
 	}
 
-	return nil
+	if len(clp.RootFS) == 0 && create {
+		errs.add("rootfs", "could not be zero")
+	}
+
+	for _, sd := range strings.Fields(strings.Replace(clp.SearchDomain, ",", " ", -1)) {
+		if !isDNSName(sd) {
+			errs.add("searchdomain", "wrong DNS name: %s", sd)
+		}
+	}
+
+	if clp.Swap < 0 {
+		errs.add("swap", "wrong value. It shuld be 0-N")
+	}
+
+	if clp.Tty < 0 || clp.Tty > 6 {
+		errs.add("tty", "wrong value. It shuld be 0-6")
+	}
+
+	if clp.VmId < 0 || (clp.VmId > 0 && (clp.VmId < 100 || clp.VmId > 999999999)) {
+		errs.add("vmid", "wrong value. It shuld be 100-999999999")
+	}
+
+	if clp.Startup.Order < 0 || clp.Startup.UpDelay < 0 || clp.Startup.DownDelay < 0 {
+		errs.add("startup", "wrong value. order, up and down shuld be 0-N")
+	}
+
+	if clp.Features.KeyCtl && !clp.Unprivileged && create {
+		errs.add("features", "keyctl could be set for unprivileged containers only")
+	}
+
+	for _, m := range clp.Features.Mount {
+		if !fsTypeRegexp.MatchString(m) {
+			errs.add("features", "mount has wrong file system type: %s", m)
+		}
+	}
+
+	for _, t := range clp.Tags {
+		if !tagRegexp.MatchString(t) {
+			errs.add("tags", "wrong value: %s", t)
+		}
+	}
+
+	if len(clp.Hookscript) > 0 && !hookscriptRegexp.MatchString(clp.Hookscript) {
+		errs.add("hookscript", "wrong value. It shuld be <storage>:snippets/<file>")
+	}
+
+	if strings.ContainsAny(clp.Timezone, " \t") {
+		errs.add("timezone", "wrong value: %s", clp.Timezone)
+	}
+
+	if clp.BWLimit < 0 {
+		errs.add("bwlimit", "wrong value. It shuld be 0-N")
+	}
+
+	netIndexes := make(map[int]bool)
+	netNames := make(map[string]bool)
+	for _, n := range clp.Networks {
+		key := "net" + strconv.Itoa(n.Index)
+		if netIndexes[n.Index] {
+			errs.add(key, "duplicate index")
+		}
+		netIndexes[n.Index] = true
+		if len(n.Name) > 0 && netNames[n.Name] {
+			errs.add(key + ".name", "duplicate interface name: %s", n.Name)
+		}
+		netNames[n.Name] = true
+		n.validate(key, &errs)
+	}
+
+	mpIndexes := make(map[int]bool)
+	mpPaths := make(map[string]bool)
+	for _, m := range clp.MountPoints {
+		key := "mp" + strconv.Itoa(m.Index)
+		if mpIndexes[m.Index] {
+			errs.add(key, "duplicate index")
+		}
+		mpIndexes[m.Index] = true
+		if len(m.Path) > 0 && mpPaths[m.Path] {
+			errs.add(key + ".mp", "duplicate mount path: %s", m.Path)
+		}
+		mpPaths[m.Path] = true
+		m.validate(key, &errs)
+	}
+
+	devIndexes := make(map[int]bool)
+	for _, d := range clp.Devices {
+		key := "dev" + strconv.Itoa(d.Index)
+		if devIndexes[d.Index] {
+			errs.add(key, "duplicate index")
+		}
+		devIndexes[d.Index] = true
+		d.validate(key, &errs)
+	}
+
+	return errs.orNil()
 }
 
 func (clp *LxcConfig) GetUrlDataValues() url.Values {
@@ -237,6 +487,15 @@ func (clp *LxcConfig) GetUrlDataValues() url.Values {
 	if clp.VmId > 0 {data.Add("vmid",strconv.Itoa(int(clp.VmId)))}
 	if len(clp.RootFS) > 0 { data.Add("rootfs",clp.RootFS) }
 	if len(clp.Startup.String()) > 0 { data.Add("startup",clp.Startup.String()) }
+	if len(clp.Features.String()) > 0 { data.Add("features",clp.Features.String()) }
+	if len(clp.Tags) > 0 { data.Add("tags",strings.Join(clp.Tags,";")) }
+	if len(clp.Hookscript) > 0 { data.Add("hookscript",clp.Hookscript) }
+	if len(clp.Timezone) > 0 { data.Add("timezone",clp.Timezone) }
+	if len(clp.SSHPublicKeys) > 0 { data.Add("ssh-public-keys",clp.SSHPublicKeys) }
+	if clp.Start { data.Add("start", "1") }
+	if clp.Unique { data.Add("unique", "1") }
+	if clp.IgnoreUnpackErrors { data.Add("ignore-unpack-errors", "1") }
+	if clp.BWLimit > 0 { data.Add("bwlimit",strconv.Itoa(clp.BWLimit)) }
 	if len(clp.Networks) > 0 {
 		for _,n := range clp.Networks {
 			if len(n.String()) >0 {
@@ -252,6 +511,233 @@ func (clp *LxcConfig) GetUrlDataValues() url.Values {
 			}
 		}
 	}
+
+	if len(clp.Devices) > 0 {
+		for _,d := range clp.Devices {
+			if len(d.String()) > 0 {
+				data.Add("dev" + strconv.Itoa(d.Index), d.String())
+			}
+		}
+	}
 	return data
 }
 
+
+type LxcMigrateOptions struct {
+	Restart bool
+	Timeout int
+	TargetStorage string
+	BWLimit int
+}
+
+func (lxc *Lxc) taskCall(method string, target string, data url.Values) (*Task, error) {
+	var taskID TaskID
+
+	httpCode, err := lxc.parent.(*Node).parent.(*Proxmox).APICall2(method, target, data, &taskID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return lxc.parent.(*Node).GetTask(taskID), nil
+}
+
+func (lxc *Lxc) Reboot(timeout int) (*Task, error) {
+	target := "nodes/" + lxc.parent.(*Node).Node + "/lxc/" + strconv.Itoa(int(lxc.VmId)) + "/status/reboot"
+
+	data := make(url.Values)
+	if timeout > 0 { data.Add("timeout", strconv.Itoa(timeout)) }
+
+	return lxc.taskCall("POST", target, data)
+}
+
+func (lxc *Lxc) GetVmId() int64 {
+	return lxc.VmId
+}
+
+func (lxc *Lxc) GetName() string {
+	return lxc.Name
+}
+
+func (lxc *Lxc) GetType() string {
+	return GUEST_TYPE_LXC
+}
+
+func (lxc *Lxc) GetTags() []string {
+	return parseTags(lxc.Tags)
+}
+
+func (lxc *Lxc) GetGuestStatus() (string, error) {
+	lxcStatus, err := lxc.GetStatus()
+	if err != nil {
+		return "", err
+	}
+
+	return lxcStatus.Status, nil
+}
+
+// StartGuest, StopGuest and ShutdownGuest wrap Start, Stop and Shutdown for
+// the Guest interface, which returns *Task.
+func (lxc *Lxc) StartGuest(skiplock bool) (*Task, error) {
+	taskID, err := lxc.Start(skiplock)
+	if err != nil {
+		return nil, err
+	}
+
+	return lxc.parent.(*Node).GetTask(*taskID), nil
+}
+
+func (lxc *Lxc) StopGuest(skiplock bool) (*Task, error) {
+	taskID, err := lxc.Stop(skiplock)
+	if err != nil {
+		return nil, err
+	}
+
+	return lxc.parent.(*Node).GetTask(*taskID), nil
+}
+
+func (lxc *Lxc) ShutdownGuest(forceStop bool, timeout int) (*Task, error) {
+	taskID, err := lxc.Shutdown(forceStop, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return lxc.parent.(*Node).GetTask(*taskID), nil
+}
+
+func (lxc *Lxc) GetConfigDigest() (string, error) {
+	target := "nodes/" + lxc.parent.(*Node).Node + "/lxc/" + strconv.Itoa(int(lxc.VmId)) + "/config"
+
+	var config struct {
+		Digest string `json:"digest"`
+	}
+
+	httpCode, err := lxc.parent.(*Node).parent.(*Proxmox).APICall2("GET",target, nil, &config, nil)
+
+	if err != nil {
+		return "", err
+	}
+	if httpCode != 200 {
+		return "", errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return config.Digest, nil
+}
+
+func (lxc *Lxc) SetTags(tags []string) error {
+	digest, err := lxc.GetConfigDigest()
+	if err != nil {
+		return err
+	}
+
+	target := "nodes/" + lxc.parent.(*Node).Node + "/lxc/" + strconv.Itoa(int(lxc.VmId)) + "/config"
+
+	data := make(url.Values)
+	data.Add("digest", digest)
+	if len(tags) > 0 { data.Add("tags", strings.Join(tags, ";")) } else { data.Add("delete", "tags") }
+
+	var result interface{}
+
+	httpCode, err := lxc.parent.(*Node).parent.(*Proxmox).APICall2("PUT",target, data, &result, nil)
+
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	lxc.Tags = strings.Join(tags, ";")
+
+	return nil
+}
+
+func (lxc *Lxc) snapshotTarget(snapname string) string {
+	target := "nodes/" + lxc.parent.(*Node).Node + "/lxc/" + strconv.Itoa(int(lxc.VmId)) + "/snapshot"
+	if len(snapname) > 0 {
+		target += "/" + url.PathEscape(snapname)
+	}
+	return target
+}
+
+func (lxc *Lxc) CreateSnapshot(snapname string, description string) (*Task, error) {
+	if !snapnameRegexp.MatchString(snapname) || snapname == SNAPSHOT_CURRENT {
+		return nil, errors.New(fmt.Sprintf("wrong snapname: %s", snapname))
+	}
+
+	return lxc.Snapshot(snapname, description)
+}
+
+func (lxc *Lxc) GetSnapshots() ([]GuestSnapshot, error) {
+	var snapshots []GuestSnapshot
+
+	httpCode, err := lxc.parent.(*Node).parent.(*Proxmox).APICall2("GET", lxc.snapshotTarget(""), nil, &snapshots, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return snapshots, nil
+}
+
+func (lxc *Lxc) GetSnapshotTree() ([]*GuestSnapshot, error) {
+	snapshots, err := lxc.GetSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSnapshotTree(snapshots), nil
+}
+
+func (lxc *Lxc) RollbackSnapshot(snapname string, start bool) (*Task, error) {
+	if len(snapname) == 0 || snapname == SNAPSHOT_CURRENT {
+		return nil, errors.New(fmt.Sprintf("wrong snapname: %s", snapname))
+	}
+
+	data := make(url.Values)
+	if start { data.Add("start", "1") }
+
+	return lxc.taskCall("POST", lxc.snapshotTarget(snapname) + "/rollback", data)
+}
+
+func (lxc *Lxc) DeleteSnapshot(snapname string, force bool) (*Task, error) {
+	if len(snapname) == 0 || snapname == SNAPSHOT_CURRENT {
+		return nil, errors.New(fmt.Sprintf("wrong snapname: %s", snapname))
+	}
+
+	data := make(url.Values)
+	if force { data.Add("force", "1") }
+
+	return lxc.taskCall("DELETE", lxc.snapshotTarget(snapname), data)
+}
+
+func (lxc *Lxc) Migrate(targetNode string, opts LxcMigrateOptions) (*Task, error) {
+	if len(targetNode) == 0 {
+		return nil, errors.New("target node could not be zero")
+	}
+
+	if opts.Timeout < 0 || opts.BWLimit < 0 {
+		return nil, errors.New("timeout and bwlimit shuld be 0-N")
+	}
+
+	target := "nodes/" + lxc.parent.(*Node).Node + "/lxc/" + strconv.Itoa(int(lxc.VmId)) + "/migrate"
+
+	data := make(url.Values)
+	data.Add("target", targetNode)
+	if opts.Restart { data.Add("restart", "1") }
+	if opts.Timeout > 0 { data.Add("timeout", strconv.Itoa(opts.Timeout)) }
+	if len(opts.TargetStorage) > 0 { data.Add("target-storage", opts.TargetStorage) }
+	if opts.BWLimit > 0 { data.Add("bwlimit", strconv.Itoa(opts.BWLimit)) }
+
+	return lxc.taskCall("POST", target, data)
+}
+
+// Containers could not be live migrated, online migration of a running
+// container is done in restart mode.
+func (lxc *Lxc) MigrateTo(targetNode string, online bool) (*Task, error) {
+	return lxc.Migrate(targetNode, LxcMigrateOptions{Restart: online && lxc.Status == GUEST_STATUS_RUNNING})
+}
@@ -1,10 +1,12 @@
 package proxmox
 
 import (
+	"net"
 	"strconv"
 	"fmt"
 	"regexp"
 	"reflect"
+	"strings"
 )
 
 const (
@@ -14,11 +16,39 @@ const (
 
 	LXC_ARCH_AMD64 = "amd64"
 	LXC_ARCH_I386 = "i386"
+	LXC_ARCH_ARM64 = "arm64"
+	LXC_ARCH_ARMHF = "armhf"
 
 	LXC_LOCK_BACKUP = "backup"
+	LXC_LOCK_CREATE = "create"
+	LXC_LOCK_DESTROYED = "destroyed"
+	LXC_LOCK_DISK = "disk"
+	LXC_LOCK_FSTRIM = "fstrim"
 	LXC_LOCK_MIGRATE = "migrate"
+	LXC_LOCK_MOUNTED = "mounted"
 	LXC_LOCK_ROLBACK = "rollback"
 	LXC_LOCK_SNAPSHOT = "snapshot"
+	LXC_LOCK_SNAPSHOT_DELETE = "snapshot-delete"
+
+	LXC_OSTYPE_DEBIAN = "debian"
+	LXC_OSTYPE_DEVUAN = "devuan"
+	LXC_OSTYPE_UBUNTU = "ubuntu"
+	LXC_OSTYPE_CENTOS = "centos"
+	LXC_OSTYPE_FEDORA = "fedora"
+	LXC_OSTYPE_OPENSUSE = "opensuse"
+	LXC_OSTYPE_ARCHLINUX = "archlinux"
+	LXC_OSTYPE_ALPINE = "alpine"
+	LXC_OSTYPE_GENTOO = "gentoo"
+	LXC_OSTYPE_NIXOS = "nixos"
+	LXC_OSTYPE_UNMANAGED = "unmanaged"
+
+	LXC_MAX_NETWORKS = 32
+	LXC_MAX_MOUNTPOINTS = 256
+	LXC_MAX_DEVICES = 256
+
+	LXC_FEATURE_SNAPSHOT = GUEST_FEATURE_SNAPSHOT
+	LXC_FEATURE_CLONE = GUEST_FEATURE_CLONE
+	LXC_FEATURE_COPY = GUEST_FEATURE_COPY
 
 )
 
@@ -37,6 +67,7 @@ type LxcBase struct {
 	NetOut int64	`json:"netout"`
 	Status string	`json:"status"`
 	Swap int64		`json:"swap"`
+	Tags string		`json:"tags"`
 	Template string	`json:"template"`
 	Type string		`json:"type"`
 	Uptime int64	`json:"uptime"`
@@ -73,6 +104,23 @@ type NetworkConfig struct {
 	Type string
 }
 
+type LxcFeatures struct {
+	Nesting bool
+	KeyCtl bool
+	Fuse bool
+	MkNod bool
+	Mount []string
+}
+
+type DeviceConfig struct {
+	Index int
+	Path string
+	Mode string
+	UID int
+	GID int
+	DenyWrite bool
+}
+
 type BaseLxcConfig struct {
 	Arch string				`json:"arch"`
 	CMode string			`json:"cmode"`
@@ -101,6 +149,13 @@ type BaseLxcConfig struct {
 	Unprivileged bool		`json:"unprivileged"`
 	VmId int64				`json:"vmid"`
 	RootFS string 			`json:"rootfs"`
+	Hookscript string		`json:"hookscript"`
+	Timezone string			`json:"timezone"`
+	SSHPublicKeys string	`json:"ssh-public-keys"`
+	Start bool				`json:"start"`
+	Unique bool				`json:"unique"`
+	IgnoreUnpackErrors bool	`json:"ignore-unpack-errors"`
+	BWLimit int				`json:"bwlimit"`
 }
 
 type LxcConfigReceiver struct {
@@ -126,7 +181,20 @@ type LxcConfigReceiver struct {
 	Net8 string `json:"net8"`
 	Net9 string `json:"net9"`
 
+	Dev0 string `json:"dev0"`
+	Dev1 string `json:"dev1"`
+	Dev2 string `json:"dev2"`
+	Dev3 string `json:"dev3"`
+	Dev4 string `json:"dev4"`
+	Dev5 string `json:"dev5"`
+	Dev6 string `json:"dev6"`
+	Dev7 string `json:"dev7"`
+	Dev8 string `json:"dev8"`
+	Dev9 string `json:"dev9"`
+
 	Startup string`json:"startup"`
+	Features string `json:"features"`
+	Tags string `json:"tags"`
 
 	Lxc []interface{} `json:"lxc"`
 
@@ -306,16 +374,230 @@ func (mp *MountPoint) String() (string) {
 	return str
 }
 
+func (lf *LxcFeatures) SetFromString(str string) {
+	if len(str) > 0 {
+		keypairs := parseKeyPairs(str)
+		for _, kp := range keypairs {
+			if len(kp) == 2 {
+				switch kp[0] {
+				case "nesting":
+					n,_ := strconv.Atoi(kp[1])
+					lf.Nesting = n == 1
+				case "keyctl":
+					k,_ := strconv.Atoi(kp[1])
+					lf.KeyCtl = k == 1
+				case "fuse":
+					f,_ := strconv.Atoi(kp[1])
+					lf.Fuse = f == 1
+				case "mknod":
+					m,_ := strconv.Atoi(kp[1])
+					lf.MkNod = m == 1
+				case "mount":
+					lf.Mount = strings.Split(kp[1], ";")
+				}
+			}
+		}
+	}
+}
+
+func (lf *LxcFeatures) String() (string) {
+	var res []string
+
+	if lf.Nesting { res = append(res,"nesting=1") }
+	if lf.KeyCtl { res = append(res,"keyctl=1") }
+	if lf.Fuse { res = append(res,"fuse=1") }
+	if lf.MkNod { res = append(res,"mknod=1") }
+	if len(lf.Mount) > 0 { res = append(res,fmt.Sprintf("mount=%s",strings.Join(lf.Mount,";"))) }
+
+	return strings.Join(res,",")
+}
+
+func (dc *DeviceConfig) SetFromString(idx int, str string) {
+	dc.Index = idx
+	if len(str)>0 {
+		keypairs := parseKeyPairs(str)
+
+		for _,kp := range keypairs {
+			if len(kp) == 2 {
+				switch kp[0] {
+				case "path":
+					dc.Path = kp[1]
+				case "mode":
+					dc.Mode = kp[1]
+				case "uid":
+					dc.UID,_ = strconv.Atoi(kp[1])
+				case "gid":
+					dc.GID,_ = strconv.Atoi(kp[1])
+				case "deny-write":
+					d,_ := strconv.Atoi(kp[1])
+					dc.DenyWrite = d == 1
+				}
+			}
+			if len(kp) == 1 {
+				dc.Path = kp[0]
+			}
+		}
+	}
+}
+
+func (dc *DeviceConfig) String() (string) {
+	var res []string
+
+	if len(dc.Path) == 0 { return "" }
+
+	res = append(res,dc.Path)
+	if len(dc.Mode) > 0 { res = append(res,fmt.Sprintf("mode=%s",dc.Mode)) }
+	if dc.UID > 0 { res = append(res,fmt.Sprintf("uid=%d",dc.UID)) }
+	if dc.GID > 0 { res = append(res,fmt.Sprintf("gid=%d",dc.GID)) }
+	if dc.DenyWrite { res = append(res,"deny-write=1") }
+
+	return strings.Join(res,",")
+}
+
+func (nc *NetworkConfig) validate(key string, errs *ValidationErrors) {
+	if nc.Index < 0 || nc.Index >= LXC_MAX_NETWORKS {
+		errs.add(key, "wrong index. It shuld be 0-%d", LXC_MAX_NETWORKS-1)
+	}
+
+	if !ifaceNameRegexp.MatchString(nc.Name) {
+		errs.add(key + ".name", "wrong interface name: %s", nc.Name)
+	}
+
+	if len(nc.Bridge) > 0 && !ifaceNameRegexp.MatchString(nc.Bridge) {
+		errs.add(key + ".bridge", "wrong bridge name: %s", nc.Bridge)
+	}
+
+	if len(nc.HWAddr) > 0 && !macAddrRegexp.MatchString(nc.HWAddr) {
+		errs.add(key + ".hwaddr", "wrong unicast MAC address: %s", nc.HWAddr)
+	}
+
+	var ip, ip6 net.IP
+	switch nc.IPAddress {
+	case "", "dhcp", "manual":
+	default:
+		var ok bool
+		if ip, ok = parseCIDR(nc.IPAddress, false); !ok {
+			errs.add(key + ".ip", "wrong value. It shuld be dhcp, manual or IPv4/CIDR")
+		}
+	}
+
+	switch nc.IPAddresV6 {
+	case "", "auto", "dhcp", "manual":
+	default:
+		var ok bool
+		if ip6, ok = parseCIDR(nc.IPAddresV6, true); !ok {
+			errs.add(key + ".ip6", "wrong value. It shuld be auto, dhcp, manual or IPv6/CIDR")
+		}
+	}
+
+	if len(nc.Gateway) > 0 {
+		if !isIPv4(nc.Gateway) {
+			errs.add(key + ".gw", "wrong IPv4 address: %s", nc.Gateway)
+		} else if ip == nil {
+			errs.add(key + ".gw", "could be set only with static IPv4 address")
+		} else if ip.Equal(net.ParseIP(nc.Gateway)) {
+			errs.add(key + ".gw", "could not be equal to interface address")
+		}
+	}
+
+	if len(nc.GatewayV6) > 0 {
+		if !isIPv6(nc.GatewayV6) {
+			errs.add(key + ".gw6", "wrong IPv6 address: %s", nc.GatewayV6)
+		} else if ip6 == nil {
+			errs.add(key + ".gw6", "could be set only with static IPv6 address")
+		} else if ip6.Equal(net.ParseIP(nc.GatewayV6)) {
+			errs.add(key + ".gw6", "could not be equal to interface address")
+		}
+	}
+
+	if nc.MTU != 0 && (nc.MTU < 64 || nc.MTU > 65535) {
+		errs.add(key + ".mtu", "wrong value. It shuld be 64-65535")
+	}
+
+	if nc.Rate < 0 {
+		errs.add(key + ".rate", "wrong value. It shuld be 0-N")
+	}
+
+	if nc.Tag != 0 && (nc.Tag < 1 || nc.Tag > 4094) {
+		errs.add(key + ".tag", "wrong VLAN tag. It shuld be 1-4094")
+	}
+
+	if len(nc.Trunks) > 0 {
+		for _, t := range strings.Split(nc.Trunks, ";") {
+			v, err := strconv.Atoi(t)
+			if err != nil || v < 0 || v > 4095 {
+				errs.add(key + ".trunks", "wrong VLAN id: %s", t)
+			}
+		}
+	}
+
+	if len(nc.Type) > 0 && nc.Type != "veth" {
+		errs.add(key + ".type", "wrong value. Posible values is: [veth|empty]")
+	}
+}
+
+func (mp *MountPoint) validate(key string, errs *ValidationErrors) {
+	if mp.Index < 0 || mp.Index >= LXC_MAX_MOUNTPOINTS {
+		errs.add(key, "wrong index. It shuld be 0-%d", LXC_MAX_MOUNTPOINTS-1)
+	}
+
+	if len(mp.Volume) == 0 {
+		errs.add(key + ".volume", "could not be zero")
+	}
+
+	if !strings.HasPrefix(mp.Path, "/") || mp.Path == "/" {
+		errs.add(key + ".mp", "wrong value. It shuld be an absolute path except /")
+	}
+
+	if mp.Size < 0 {
+		errs.add(key + ".size", "wrong value. It shuld be 0-N")
+	}
+}
+
+func (dc *DeviceConfig) validate(key string, errs *ValidationErrors) {
+	if dc.Index < 0 || dc.Index >= LXC_MAX_DEVICES {
+		errs.add(key, "wrong index. It shuld be 0-%d", LXC_MAX_DEVICES-1)
+	}
+
+	if !strings.HasPrefix(dc.Path, "/dev/") {
+		errs.add(key + ".path", "wrong value. It shuld be /dev/...")
+	}
+
+	if len(dc.Mode) > 0 && !octalModeRegexp.MatchString(dc.Mode) {
+		errs.add(key + ".mode", "wrong value. It shuld be octal access mode")
+	}
+
+	if dc.UID < 0 {
+		errs.add(key + ".uid", "wrong value. It shuld be 0-N")
+	}
+
+	if dc.GID < 0 {
+		errs.add(key + ".gid", "wrong value. It shuld be 0-N")
+	}
+}
+
+func parseTags(str string) []string {
+	var tags []string
+
+	for _, t := range strings.FieldsFunc(str, func(r rune) bool { return r == ';' || r == ',' || r == ' ' }) {
+		tags = append(tags, t)
+	}
+
+	return tags
+}
+
 func (lcr *LxcConfigReceiver) Parse() (*LxcConfig){
 
 	lxcConfig := LxcConfig{ BaseLxcConfig: lcr.BaseLxcConfig}
 
 	lxcConfig.Startup.SetFromString(lcr.Startup)
+	lxcConfig.Features.SetFromString(lcr.Features)
+	lxcConfig.Tags = parseTags(lcr.Tags)
 
 	rlcrv := reflect.ValueOf(*lcr)
 	rlcrt := reflect.TypeOf(*lcr)
 
-	r := regexp.MustCompile("^(Mp|Net)(\\d+)$")
+	r := regexp.MustCompile("^(Mp|Net|Dev)(\\d+)$")
 
 	for i:=0; i<rlcrv.NumField(); i++ {
 		if r.MatchString(rlcrt.Field(i).Name) {
@@ -337,6 +619,14 @@ func (lcr *LxcConfigReceiver) Parse() (*LxcConfig){
 					nc.SetFromString(idx,val)
 					lxcConfig.Networks = append(lxcConfig.Networks,nc)
 				}
+			case "Dev":
+				val := fmt.Sprintf("%v",rlcrv.Field(i))
+				if len(val)>0 {
+					dc := DeviceConfig{}
+					idx, _ := strconv.Atoi(sm[2])
+					dc.SetFromString(idx,val)
+					lxcConfig.Devices = append(lxcConfig.Devices,dc)
+				}
 			}
 		}
 	}
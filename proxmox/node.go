@@ -4,7 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 
@@ -14,8 +17,15 @@ const (
 	BACKUP_MODE_STOP BackupMode = 2
 	BACKUP_COMP_LZO BackupComp = 0
 	BACKUP_COMP_GZIP BackupComp = 1
+
+	GUEST_PROGRESS_RUNNING = "running"
+	GUEST_PROGRESS_OK = "ok"
+	GUEST_PROGRESS_ERROR = "error"
 )
 
+var bulkActionRegexp = regexp.MustCompile("^(Starting|Stopping|Starting Migration of|Migrating) (VM|CT) (\\d+)")
+var bulkGuestRegexp = regexp.MustCompile("\\b(VM|CT) (\\d+)\\b")
+
 
 type BackupComp int
 type BackupMode int
@@ -50,6 +60,36 @@ type USBDevice struct {
 	Vendid string		`json:"vendid"`
 }
 
+type PCIDevice struct {
+	Id string					`json:"id"`
+	Class string				`json:"class"`
+	Vendor string				`json:"vendor"`
+	Device string				`json:"device"`
+	VendorName string			`json:"vendor_name"`
+	DeviceName string			`json:"device_name"`
+	SubsystemVendor string		`json:"subsystem_vendor"`
+	SubsystemDevice string		`json:"subsystem_device"`
+	SubsystemVendorName string	`json:"subsystem_vendor_name"`
+	SubsystemDeviceName string	`json:"subsystem_device_name"`
+	IOMMUGroup PVEInt			`json:"iommugroup"`
+	MDev PVEBool				`json:"mdev"`
+}
+
+type PCIMdevType struct {
+	Type string			`json:"type"`
+	Available PVEInt	`json:"available"`
+	Description string	`json:"description"`
+	Name string			`json:"name"`
+}
+
+type GuestProgress struct {
+	VmId int64
+	Type string
+	Action string
+	Status string
+	Message string
+}
+
 type LVMVolumeGroup struct {
 	Free int64 `json:"free"`
 	Size int64 `json:"size"`
@@ -140,6 +180,64 @@ func (n *Node) GetLxc(vmid int64) (*Lxc,error) {
 	return nil, errors.New("Lxc container VMID: " + strconv.Itoa(int(vmid)) + " not found.")
 }
 
+func (n *Node) GetQemuList() ([]Qemu, error) {
+	target := "nodes/" + n.Node + "/qemu"
+
+	var qemuList []Qemu
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, nil, &qemuList, n)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return qemuList, nil
+}
+
+func (n *Node) GetGuests() ([]Guest, error) {
+	lxcList, err := n.GetLxcList()
+	if err != nil {
+		return nil, err
+	}
+
+	qemuList, err := n.GetQemuList()
+	if err != nil {
+		return nil, err
+	}
+
+	var guests []Guest
+	for i := range lxcList {
+		guests = append(guests, &lxcList[i])
+	}
+	for i := range qemuList {
+		guests = append(guests, &qemuList[i])
+	}
+
+	sort.Slice(guests, func(i, j int) bool {
+		return guests[i].GetVmId() < guests[j].GetVmId()
+	})
+
+	return guests, nil
+}
+
+func (n *Node) GetQemu(vmid int64) (*Qemu, error) {
+	qemuList, err := n.GetQemuList()
+	if err != nil {
+		return nil, err
+	}
+
+	for _,v := range qemuList {
+		if v.VmId == vmid {
+			qemu := v
+			return &qemu, nil
+		}
+	}
+
+	return nil, errors.New("Qemu VM VMID: " + strconv.Itoa(int(vmid)) + " not found.")
+}
+
 func (n *Node) RemoveLxc(vmid int64) (*TaskID, error) {
 
 	target := "nodes/" + n.Node + "/lxc/" + strconv.Itoa(int(vmid))
@@ -176,14 +274,11 @@ func (n *Node) CreateLxc(lxcParams LxcConfig) (*TaskID, error) {
 
 	target := "nodes/" + n.Node + "/lxc"
 
-	apitarget,err := n.GetProxmox().MakeAPITarget(target)
-	if err != nil {
-		return nil,err
-	}
-
 	data := lxcParams.GetUrlDataValues()
 
-	responseData, httpCode, err := n.parent.(*Proxmox).APICall("POST", apitarget, data)
+	var taskID TaskID
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("POST", target, data, &taskID, nil)
 	if err != nil {
 		return nil,err
 	}
@@ -191,16 +286,45 @@ func (n *Node) CreateLxc(lxcParams LxcConfig) (*TaskID, error) {
 		return nil,errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
 	}
 
+	return &taskID, nil
+
+}
 
+func (n *Node) taskCall(method string, target string, data url.Values) (*Task, error) {
 	var taskID TaskID
 
-	jsonErr := n.GetProxmox().DataUnmarshal(responseData,&taskID,nil)
-	if jsonErr != nil {
-		return nil,jsonErr
+	httpCode, err := n.parent.(*Proxmox).APICall2(method, target, data, &taskID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
 	}
 
-	return &taskID, nil
+	return n.GetTask(taskID), nil
+}
 
+func (n *Node) CreateQemu(qemuParams QemuConfig) (*Task, error) {
+	err := qemuParams.Validate()
+	if err != nil {
+		return nil,err
+	}
+
+	target := "nodes/" + n.Node + "/qemu"
+
+	data := qemuParams.GetUrlDataValues()
+
+	return n.taskCall("POST", target, data)
+}
+
+func (n *Node) RemoveQemu(vmid int64, purge bool, destroyUnreferenced bool) (*Task, error) {
+	target := "nodes/" + n.Node + "/qemu/" + strconv.Itoa(int(vmid))
+
+	data := make(url.Values)
+	if purge { data.Add("purge", "1") }
+	if destroyUnreferenced { data.Add("destroy-unreferenced-disks", "1") }
+
+	return n.taskCall("DELETE", target, data)
 }
 
 func (n *Node) VZDump(vmid int64, storage Storage, mode BackupMode, comp BackupComp, remove bool) (*TaskID, error) {
@@ -272,6 +396,112 @@ func (n *Node) GetTasks() ([]Task, error) {
 	return tasks, nil
 }
 
+func (n *Node) ScanPCI(verbose bool) ([]PCIDevice, error) {
+	data := make(url.Values)
+	if verbose { data.Add("verbose", "1") } else { data.Add("verbose", "0") }
+
+	return n.scanPCI(data)
+}
+
+func (n *Node) scanPCI(data url.Values) ([]PCIDevice, error) {
+	target := "nodes/" + n.Node + "/hardware/pci"
+
+	var devices []PCIDevice
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, data, &devices, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return devices,nil
+}
+
+func (n *Node) GetPCIMdevTypes(pciId string) ([]PCIMdevType, error) {
+	if len(pciId) == 0 {
+		return nil, errors.New("pci id could not be zero")
+	}
+
+	target := "nodes/" + n.Node + "/hardware/pci/" + url.PathEscape(pciId) + "/mdev"
+
+	var mdevTypes []PCIMdevType
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, nil, &mdevTypes, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return mdevTypes,nil
+}
+
+func (n *Node) GetIOMMUGroup(pciId string) ([]PCIDevice, error) {
+	// An empty class blacklist keeps bridges and other devices PVE hides by
+	// default, they are still part of the group.
+	data := make(url.Values)
+	data.Add("verbose", "0")
+	data.Add("pci-class-blacklist", "")
+
+	devices, err := n.scanPCI(data)
+	if err != nil {
+		return nil, err
+	}
+
+	group := PVEInt(-1)
+	for _, d := range devices {
+		if d.Id == pciId {
+			group = d.IOMMUGroup
+		}
+	}
+	if group < 0 {
+		return nil, errors.New(fmt.Sprintf("PCI device %s not found or not in IOMMU group", pciId))
+	}
+
+	var res []PCIDevice
+	for _, d := range devices {
+		if d.IOMMUGroup == group {
+			res = append(res, d)
+		}
+	}
+
+	return res, nil
+}
+
+func (d *PCIDevice) ToQemuHostPCI(index int, allFunctions bool, mdev string) QemuHostPCI {
+	host := strings.TrimPrefix(d.Id, "0000:")
+	if allFunctions {
+		if i := strings.LastIndex(host, "."); i > 0 {
+			host = host[:i]
+		}
+	}
+
+	return QemuHostPCI{
+		Index: index,
+		Host: host,
+		MDev: mdev,
+		RomBar: true,
+	}
+}
+
+func (d *USBDevice) ToQemuUSB(index int, byPort bool) QemuUSB {
+	qu := QemuUSB{Index: index}
+
+	if byPort {
+		qu.Host = strconv.Itoa(d.Busnum) + "-" + d.Usbpath
+	} else {
+		qu.Host = strings.TrimPrefix(d.Vendid, "0x") + ":" + strings.TrimPrefix(d.Prodid, "0x")
+	}
+
+	speed, _ := strconv.Atoi(d.Speed)
+	qu.USB3 = speed >= 5000
+
+	return qu
+}
+
 func (n *Node) ScanUSB() ([]USBDevice, error) {
 	target := "nodes/" + n.Node + "/scan/usb"
 	var devices []USBDevice
@@ -300,4 +530,151 @@ func (n *Node) ScanLVM() ([]LVMVolumeGroup, error) {
 	}
 
 	return groups,nil
-}
\ No newline at end of file
+}
+
+func (n *Node) GetTask(upid TaskID) *Task {
+	parts := strings.Split(string(upid), ":")
+
+	task := &Task{BasicObject: NewBasicObject(n)}
+	task.UPid = upid
+	task.Node = n.Node
+	if len(parts) > 6 {
+		task.Type = parts[5]
+		task.Id = parts[6]
+	}
+
+	return task
+}
+
+func vmidList(vms []int64) string {
+	var res []string
+	for _, v := range vms {
+		res = append(res, strconv.Itoa(int(v)))
+	}
+	return strings.Join(res, ",")
+}
+
+func (n *Node) StartAll(vms []int64, force bool) (*Task, error) {
+	target := "nodes/" + n.Node + "/startall"
+
+	data := make(url.Values)
+	if len(vms) > 0 { data.Add("vms", vmidList(vms)) }
+	if force { data.Add("force", "1") }
+
+	return n.taskCall("POST", target, data)
+}
+
+func (n *Node) StopAll(vms []int64, forceStop bool, timeout int) (*Task, error) {
+	target := "nodes/" + n.Node + "/stopall"
+
+	data := make(url.Values)
+	if len(vms) > 0 { data.Add("vms", vmidList(vms)) }
+	if forceStop { data.Add("force-stop", "1") }
+	if timeout > 0 { data.Add("timeout", strconv.Itoa(timeout)) }
+
+	return n.taskCall("POST", target, data)
+}
+
+func (n *Node) MigrateAll(targetNode string, vms []int64, maxWorkers int, withLocalDisks bool) (*Task, error) {
+	if len(targetNode) == 0 {
+		return nil, errors.New("target node could not be zero")
+	}
+	if targetNode == n.Node {
+		return nil, errors.New("target node could not be equal to source node")
+	}
+
+	target := "nodes/" + n.Node + "/migrateall"
+
+	data := make(url.Values)
+	data.Add("target", targetNode)
+	if len(vms) > 0 { data.Add("vms", vmidList(vms)) }
+	if maxWorkers > 0 { data.Add("maxworkers", strconv.Itoa(maxWorkers)) }
+	if withLocalDisks { data.Add("with-local-disks", "1") }
+
+	return n.taskCall("POST", target, data)
+}
+
+func (n *Node) GetBulkProgress(upid TaskID) ([]GuestProgress, error) {
+	taskLog, err := n.GetTask(upid).GetLog(0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBulkProgress(taskLog), nil
+}
+
+// ParseBulkProgress derives per guest progress from a startall, stopall or
+// migrateall task log. PVE has no structured status for these tasks, so it
+// relies on the log lines printed by PVE::API2::Nodes:
+//
+//	"Starting VM|CT <vmid>"                 start of a guest (startall)
+//	"Stopping VM|CT <vmid>"                 start of a guest (stopall)
+//	"Starting Migration of VM|CT <vmid>"    start of a guest (migrateall, older PVE)
+//	"Migrating VM|CT <vmid>"                start of a guest (migrateall)
+//	"... VM|CT <vmid> ..." containing "fail", "error" or "unable"
+//	                                        failure of a guest seen before
+//	"TASK OK" / "TASK ERROR: ..."           end of the task
+//
+// Any other line is ignored, so guests only show up after their action line and
+// a failure worded differently leaves the guest running, then ok at the end.
+func ParseBulkProgress(taskLog []TaskLogLine) []GuestProgress {
+	var progress []GuestProgress
+	index := make(map[int64]int)
+	finished := false
+
+	for _, l := range taskLog {
+		if strings.HasPrefix(l.T, "TASK OK") || strings.HasPrefix(l.T, "TASK ERROR") {
+			finished = true
+			continue
+		}
+
+		lower := strings.ToLower(l.T)
+		failed := strings.Contains(lower, "fail") || strings.Contains(lower, "error") || strings.Contains(lower, "unable")
+
+		if sm := bulkActionRegexp.FindStringSubmatch(l.T); sm != nil {
+			vmid, _ := strconv.ParseInt(sm[3], 10, 64)
+
+			gp := GuestProgress{VmId: vmid, Type: "qemu", Status: GUEST_PROGRESS_RUNNING, Message: l.T}
+			if sm[2] == "CT" { gp.Type = "lxc" }
+			switch sm[1] {
+			case "Starting":
+				gp.Action = "start"
+			case "Stopping":
+				gp.Action = "stop"
+			default:
+				gp.Action = "migrate"
+			}
+			if failed { gp.Status = GUEST_PROGRESS_ERROR }
+
+			if i, ok := index[vmid]; ok {
+				progress[i] = gp
+			} else {
+				index[vmid] = len(progress)
+				progress = append(progress, gp)
+			}
+			continue
+		}
+
+		if !failed {
+			continue
+		}
+
+		if sm := bulkGuestRegexp.FindStringSubmatch(l.T); sm != nil {
+			vmid, _ := strconv.ParseInt(sm[2], 10, 64)
+			if i, ok := index[vmid]; ok {
+				progress[i].Status = GUEST_PROGRESS_ERROR
+				progress[i].Message = l.T
+			}
+		}
+	}
+
+	if finished {
+		for i := range progress {
+			if progress[i].Status == GUEST_PROGRESS_RUNNING {
+				progress[i].Status = GUEST_PROGRESS_OK
+			}
+		}
+	}
+
+	return progress
+}
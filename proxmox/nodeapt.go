@@ -0,0 +1,243 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+const (
+	APT_REPO_ENTERPRISE = "enterprise"
+	APT_REPO_NO_SUBSCRIPTION = "no-subscription"
+	APT_REPO_TEST = "test"
+)
+
+var aptRepoHandleRegexp = regexp.MustCompile("^[a-z0-9]+(-[a-z0-9]+)*$")
+
+type AptPackage struct {
+	Package string		`json:"Package"`
+	Title string		`json:"Title"`
+	Description string	`json:"Description"`
+	Version string		`json:"Version"`
+	OldVersion string	`json:"OldVersion"`
+	Priority string		`json:"Priority"`
+	Section string		`json:"Section"`
+	Arch string			`json:"Arch"`
+	Origin string		`json:"Origin"`
+}
+
+type AptPackageVersion struct {
+	AptPackage
+	CurrentState string		`json:"CurrentState"`
+	RunningKernel string	`json:"RunningKernel"`
+	ManagerVersion string	`json:"ManagerVersion"`
+}
+
+type AptRepositoryOption struct {
+	Key string			`json:"Key"`
+	Values []string		`json:"Values"`
+}
+
+type AptRepository struct {
+	Types []string					`json:"Types"`
+	URIs []string					`json:"URIs"`
+	Suites []string					`json:"Suites"`
+	Components []string				`json:"Components"`
+	Options []AptRepositoryOption	`json:"Options"`
+	Comment string					`json:"Comment"`
+	FileType string					`json:"FileType"`
+	Enabled PVEBool					`json:"Enabled"`
+}
+
+type AptRepositoryFile struct {
+	Path string						`json:"path"`
+	FileType string					`json:"file-type"`
+	Repositories []AptRepository	`json:"repositories"`
+	Digest []PVEInt					`json:"digest"`
+}
+
+type AptRepositoryError struct {
+	Path string		`json:"path"`
+	Error string	`json:"error"`
+}
+
+type AptRepositoryInfo struct {
+	Path string			`json:"path"`
+	Index string		`json:"index"`
+	Kind string			`json:"kind"`
+	Message string		`json:"message"`
+	Property string		`json:"property"`
+}
+
+type AptStandardRepository struct {
+	Handle string		`json:"handle"`
+	Name string			`json:"name"`
+	Status PVEBool		`json:"status"`
+	Description string	`json:"description"`
+}
+
+type AptRepositories struct {
+	Files []AptRepositoryFile					`json:"files"`
+	Errors []AptRepositoryError					`json:"errors"`
+	Digest string								`json:"digest"`
+	Infos []AptRepositoryInfo					`json:"infos"`
+	StandardRepos []AptStandardRepository		`json:"standard-repos"`
+}
+
+func (n *Node) UpdateAptIndex(notify bool, quiet bool) (*Task, error) {
+	target := "nodes/" + n.Node + "/apt/update"
+
+	data := make(url.Values)
+	if notify { data.Add("notify", "1") }
+	if quiet { data.Add("quiet", "1") }
+
+	var taskID TaskID
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("POST", target, data, &taskID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return n.GetTask(taskID), nil
+}
+
+func (n *Node) GetAptUpdates() ([]AptPackage, error) {
+	target := "nodes/" + n.Node + "/apt/update"
+
+	var packages []AptPackage
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, nil, &packages, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return packages, nil
+}
+
+func (n *Node) GetAptChangelog(name string, version string) (string, error) {
+	if len(name) == 0 {
+		return "", errors.New("package name could not be zero")
+	}
+
+	target := "nodes/" + n.Node + "/apt/changelog"
+
+	data := make(url.Values)
+	data.Add("name", name)
+	if len(version) > 0 { data.Add("version", version) }
+
+	var changelog string
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, data, &changelog, nil)
+	if err != nil {
+		return "", err
+	}
+	if httpCode != 200 {
+		return "", errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return changelog, nil
+}
+
+func (n *Node) GetAptVersions() ([]AptPackageVersion, error) {
+	target := "nodes/" + n.Node + "/apt/versions"
+
+	var versions []AptPackageVersion
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, nil, &versions, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return versions, nil
+}
+
+func (n *Node) GetAptRepositories() (*AptRepositories, error) {
+	target := "nodes/" + n.Node + "/apt/repositories"
+
+	var repositories AptRepositories
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, nil, &repositories, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return &repositories, nil
+}
+
+func (n *Node) SetAptRepositoryEnabled(path string, index int, enabled bool, digest string) error {
+	if len(path) == 0 {
+		return errors.New("path could not be zero")
+	}
+	if index < 0 {
+		return errors.New("index could not be negative")
+	}
+
+	target := "nodes/" + n.Node + "/apt/repositories"
+
+	data := make(url.Values)
+	data.Add("path", path)
+	data.Add("index", strconv.Itoa(index))
+	if enabled { data.Add("enabled", "1") } else { data.Add("enabled", "0") }
+	if len(digest) > 0 { data.Add("digest", digest) }
+
+	var result interface{}
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("POST", target, data, &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return nil
+}
+
+// AddAptStandardRepository adds a repository by its handle, see
+// AptRepositories.StandardRepos for the handles known to the node.
+func (n *Node) AddAptStandardRepository(handle string, digest string) error {
+	if !aptRepoHandleRegexp.MatchString(handle) {
+		return errors.New(fmt.Sprintf("wrong repository handle: %s", handle))
+	}
+
+	target := "nodes/" + n.Node + "/apt/repositories"
+
+	data := make(url.Values)
+	data.Add("handle", handle)
+	if len(digest) > 0 { data.Add("digest", digest) }
+
+	var result interface{}
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("PUT", target, data, &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return nil
+}
+
+func (r *AptRepositories) StandardRepository(handle string) *AptStandardRepository {
+	for i := range r.StandardRepos {
+		if r.StandardRepos[i].Handle == handle {
+			return &r.StandardRepos[i]
+		}
+	}
+	return nil
+}
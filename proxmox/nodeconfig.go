@@ -0,0 +1,290 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	NODE_MAX_DNS_SERVERS = 3
+)
+
+var timezoneRegexp = regexp.MustCompile("^[A-Za-z0-9_+\\-]+(/[A-Za-z0-9_+\\-]+)*$")
+
+type NodeDNS struct {
+	Search string	`json:"search"`
+	DNS1 string		`json:"dns1"`
+	DNS2 string		`json:"dns2"`
+	DNS3 string		`json:"dns3"`
+}
+
+type HostsEntry struct {
+	IP string
+	Names []string
+	Comment string
+}
+
+type NodeHosts struct {
+	Entries []HostsEntry
+	Digest string
+}
+
+type NodeTime struct {
+	Timezone string		`json:"timezone"`
+	Time PVEInt			`json:"time"`
+	LocalTime PVEInt	`json:"localtime"`
+}
+
+func (nd *NodeDNS) Servers() []string {
+	var servers []string
+	for _, s := range []string{nd.DNS1, nd.DNS2, nd.DNS3} {
+		if len(s) > 0 {
+			servers = append(servers, s)
+		}
+	}
+	return servers
+}
+
+func (n *Node) GetDNS() (*NodeDNS, error) {
+	target := "nodes/" + n.Node + "/dns"
+
+	var nodeDNS NodeDNS
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, nil, &nodeDNS, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return &nodeDNS, nil
+}
+
+func (n *Node) SetDNS(search string, servers ...string) error {
+	var errs ValidationErrors
+
+	if !isDNSName(search) {
+		errs.add("search", "wrong domain name: %s", search)
+	}
+
+	if len(servers) > NODE_MAX_DNS_SERVERS {
+		errs.add("dns", "too many servers. It shuld be 0-%d", NODE_MAX_DNS_SERVERS)
+	}
+
+	for _, s := range servers {
+		if !isIPv4(s) && !isIPv6(s) {
+			errs.add("dns", "wrong IP address: %s", s)
+		}
+	}
+
+	if err := errs.orNil(); err != nil {
+		return err
+	}
+
+	target := "nodes/" + n.Node + "/dns"
+
+	data := make(url.Values)
+	data.Add("search", search)
+	for i, s := range servers {
+		data.Add(fmt.Sprintf("dns%d", i+1), s)
+	}
+
+	var result interface{}
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("PUT", target, data, &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return nil
+}
+
+func (he *HostsEntry) String() string {
+	var res []string
+
+	if len(he.IP) > 0 {
+		res = append(res, he.IP)
+		res = append(res, he.Names...)
+	}
+	if len(he.Comment) > 0 {
+		res = append(res, "#" + he.Comment)
+	}
+
+	return strings.Join(res, " ")
+}
+
+func ParseHosts(content string) []HostsEntry {
+	var entries []HostsEntry
+
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	for _, line := range lines {
+		entry := HostsEntry{}
+
+		if i := strings.Index(line, "#"); i >= 0 {
+			entry.Comment = line[i+1:]
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			entry.IP = fields[0]
+			entry.Names = fields[1:]
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func (nh *NodeHosts) String() string {
+	var lines []string
+	for _, e := range nh.Entries {
+		lines = append(lines, e.String())
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (nh *NodeHosts) Lookup(name string) []string {
+	var ips []string
+	for _, e := range nh.Entries {
+		for _, n := range e.Names {
+			if n == name {
+				ips = append(ips, e.IP)
+			}
+		}
+	}
+	return ips
+}
+
+func (nh *NodeHosts) Validate() error {
+	var errs ValidationErrors
+
+	for i, e := range nh.Entries {
+		key := fmt.Sprintf("line%d", i+1)
+
+		if len(e.IP) == 0 {
+			if len(e.Names) > 0 {
+				errs.add(key, "names without IP address")
+			}
+			continue
+		}
+
+		if !isIPv4(e.IP) && !isIPv6(e.IP) {
+			errs.add(key, "wrong IP address: %s", e.IP)
+		}
+
+		if len(e.Names) == 0 {
+			errs.add(key, "IP address %s without names", e.IP)
+		}
+
+		for _, n := range e.Names {
+			if !isDNSName(n) {
+				errs.add(key, "wrong host name: %s", n)
+			}
+		}
+	}
+
+	return errs.orNil()
+}
+
+func (n *Node) GetHosts() (*NodeHosts, error) {
+	target := "nodes/" + n.Node + "/hosts"
+
+	var result struct {
+		Data string		`json:"data"`
+		Digest string	`json:"digest"`
+	}
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, nil, &result, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return &NodeHosts{Entries: ParseHosts(result.Data), Digest: result.Digest}, nil
+}
+
+func (n *Node) SetHosts(nodeHosts *NodeHosts) error {
+	err := nodeHosts.Validate()
+	if err != nil {
+		return err
+	}
+
+	target := "nodes/" + n.Node + "/hosts"
+
+	data := make(url.Values)
+	data.Add("data", nodeHosts.String())
+	if len(nodeHosts.Digest) > 0 { data.Add("digest", nodeHosts.Digest) }
+
+	var result interface{}
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("POST", target, data, &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return nil
+}
+
+func (nt *NodeTime) UTC() time.Time {
+	return time.Unix(int64(nt.Time), 0).UTC()
+}
+
+// PVE returns localtime as seconds since epoch shifted by the node time zone offset.
+func (nt *NodeTime) Local() time.Time {
+	offset := int(nt.LocalTime - nt.Time)
+	return time.Unix(int64(nt.Time), 0).In(time.FixedZone(nt.Timezone, offset))
+}
+
+func (n *Node) GetTime() (*NodeTime, error) {
+	target := "nodes/" + n.Node + "/time"
+
+	var nodeTime NodeTime
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, nil, &nodeTime, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return &nodeTime, nil
+}
+
+func (n *Node) SetTimezone(timezone string) error {
+	if !timezoneRegexp.MatchString(timezone) {
+		return errors.New(fmt.Sprintf("wrong time zone: %s", timezone))
+	}
+
+	target := "nodes/" + n.Node + "/time"
+
+	data := make(url.Values)
+	data.Add("timezone", timezone)
+
+	var result interface{}
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("PUT", target, data, &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return nil
+}
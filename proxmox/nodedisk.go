@@ -0,0 +1,345 @@
+package proxmox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	DISK_TYPE_HDD = "hdd"
+	DISK_TYPE_SSD = "ssd"
+	DISK_TYPE_NVME = "nvme"
+	DISK_TYPE_USB = "usb"
+	DISK_TYPE_UNKNOWN = "unknown"
+
+	DISK_LIST_TYPE_UNUSED = "unused"
+	DISK_LIST_TYPE_JOURNAL = "journal_disks"
+
+	DISK_HEALTH_PASSED = "PASSED"
+	DISK_HEALTH_OK = "OK"
+
+	DISK_WEAROUT_UNKNOWN = -1
+
+	ZFS_RAID_SINGLE = "single"
+	ZFS_RAID_MIRROR = "mirror"
+	ZFS_RAID_RAID10 = "raid10"
+	ZFS_RAID_RAIDZ = "raidz"
+	ZFS_RAID_RAIDZ2 = "raidz2"
+	ZFS_RAID_RAIDZ3 = "raidz3"
+
+	ZFS_COMPRESSION_ON = "on"
+	ZFS_COMPRESSION_OFF = "off"
+	ZFS_COMPRESSION_LZ4 = "lz4"
+	ZFS_COMPRESSION_ZSTD = "zstd"
+	ZFS_COMPRESSION_GZIP = "gzip"
+	ZFS_COMPRESSION_ZLE = "zle"
+	ZFS_COMPRESSION_LZJB = "lzjb"
+
+	DIRECTORY_FS_EXT4 = "ext4"
+	DIRECTORY_FS_XFS = "xfs"
+)
+
+var diskDevPathRegexp = regexp.MustCompile("^/dev/[A-Za-z0-9/_\\-]+$")
+var diskStorageNameRegexp = regexp.MustCompile("^[A-Za-z][A-Za-z0-9\\-_.]*[A-Za-z0-9]$")
+
+// DiskWearout is the remaining SSD life in percent or DISK_WEAROUT_UNKNOWN
+// when PVE reports N/A.
+type DiskWearout int64
+
+type NodeDisk struct {
+	DevPath string			`json:"devpath"`
+	Type string				`json:"type"`
+	Model string			`json:"model"`
+	Serial string			`json:"serial"`
+	Vendor string			`json:"vendor"`
+	WWN string				`json:"wwn"`
+	Size PVEInt				`json:"size"`
+	RPM PVEInt				`json:"rpm"`
+	Health string			`json:"health"`
+	Wearout DiskWearout		`json:"wearout"`
+	Used string				`json:"used"`
+	GPT PVEBool				`json:"gpt"`
+	Mounted PVEBool			`json:"mounted"`
+	OSDId PVEInt			`json:"osdid"`
+	Parent string			`json:"parent"`
+}
+
+type DiskSmartAttribute struct {
+	Id string			`json:"id"`
+	Name string			`json:"name"`
+	Value PVEInt		`json:"value"`
+	Worst PVEInt		`json:"worst"`
+	Threshold PVEInt	`json:"threshold"`
+	Fail string			`json:"fail"`
+	Flags string		`json:"flags"`
+	Raw string			`json:"raw"`
+	Normalized PVEInt	`json:"normalized"`
+}
+
+type DiskSmart struct {
+	Health string					`json:"health"`
+	Type string						`json:"type"`
+	Attributes []DiskSmartAttribute	`json:"attributes"`
+	Text string						`json:"text"`
+}
+
+type ZFSPoolOptions struct {
+	Name string
+	RaidLevel string
+	Devices []string
+	Ashift int
+	Compression string
+	AddStorage bool
+}
+
+func (w *DiskWearout) UnmarshalJSON(data []byte) error {
+	str := string(bytes.Trim(data, "\""))
+
+	v, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		*w = DISK_WEAROUT_UNKNOWN
+		return nil
+	}
+
+	*w = DiskWearout(v)
+
+	return nil
+}
+
+func (ds *DiskSmart) IsHealthy() bool {
+	return ds.Health == DISK_HEALTH_PASSED || ds.Health == DISK_HEALTH_OK
+}
+
+func (n *Node) GetDisks(listType string, includePartitions bool, skipSmart bool) ([]NodeDisk, error) {
+	switch listType {
+	case DISK_LIST_TYPE_UNUSED, DISK_LIST_TYPE_JOURNAL, "":
+	default:
+		return nil, errors.New(fmt.Sprintf("type has wrong value. Posible values is: [%s|%s|empty]", DISK_LIST_TYPE_UNUSED, DISK_LIST_TYPE_JOURNAL))
+	}
+
+	target := "nodes/" + n.Node + "/disks/list"
+
+	data := make(url.Values)
+	if len(listType) > 0 { data.Add("type", listType) }
+	if includePartitions { data.Add("include-partitions", "1") }
+	if skipSmart { data.Add("skipsmart", "1") }
+
+	var disks []NodeDisk
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, data, &disks, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return disks, nil
+}
+
+func (n *Node) GetDiskSmart(disk string, healthOnly bool) (*DiskSmart, error) {
+	if !diskDevPathRegexp.MatchString(disk) {
+		return nil, errors.New(fmt.Sprintf("wrong disk: %s", disk))
+	}
+
+	target := "nodes/" + n.Node + "/disks/smart"
+
+	data := make(url.Values)
+	data.Add("disk", disk)
+	if healthOnly { data.Add("healthonly", "1") }
+
+	var smart DiskSmart
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, data, &smart, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return &smart, nil
+}
+
+func (n *Node) diskTaskCall(method string, target string, data url.Values) (*Task, error) {
+	var taskID TaskID
+
+	httpCode, err := n.parent.(*Proxmox).APICall2(method, target, data, &taskID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return n.GetTask(taskID), nil
+}
+
+func (n *Node) InitDiskGPT(disk string, uuid string) (*Task, error) {
+	if !diskDevPathRegexp.MatchString(disk) {
+		return nil, errors.New(fmt.Sprintf("wrong disk: %s", disk))
+	}
+
+	target := "nodes/" + n.Node + "/disks/initgpt"
+
+	data := make(url.Values)
+	data.Add("disk", disk)
+	if len(uuid) > 0 { data.Add("uuid", uuid) }
+
+	return n.diskTaskCall("POST", target, data)
+}
+
+func (n *Node) WipeDisk(disk string) (*Task, error) {
+	if !diskDevPathRegexp.MatchString(disk) {
+		return nil, errors.New(fmt.Sprintf("wrong disk: %s", disk))
+	}
+
+	target := "nodes/" + n.Node + "/disks/wipedisk"
+
+	data := make(url.Values)
+	data.Add("disk", disk)
+
+	return n.diskTaskCall("PUT", target, data)
+}
+
+func validateDiskStorage(name string, device string) error {
+	var errs ValidationErrors
+
+	if !diskStorageNameRegexp.MatchString(name) {
+		errs.add("name", "wrong storage name: %s", name)
+	}
+	if !diskDevPathRegexp.MatchString(device) {
+		errs.add("device", "wrong device: %s", device)
+	}
+
+	return errs.orNil()
+}
+
+func (n *Node) CreateLVM(name string, device string, addStorage bool) (*Task, error) {
+	err := validateDiskStorage(name, device)
+	if err != nil {
+		return nil, err
+	}
+
+	target := "nodes/" + n.Node + "/disks/lvm"
+
+	data := make(url.Values)
+	data.Add("name", name)
+	data.Add("device", device)
+	if addStorage { data.Add("add_storage", "1") }
+
+	return n.diskTaskCall("POST", target, data)
+}
+
+func (n *Node) CreateLVMThin(name string, device string, addStorage bool) (*Task, error) {
+	err := validateDiskStorage(name, device)
+	if err != nil {
+		return nil, err
+	}
+
+	target := "nodes/" + n.Node + "/disks/lvmthin"
+
+	data := make(url.Values)
+	data.Add("name", name)
+	data.Add("device", device)
+	if addStorage { data.Add("add_storage", "1") }
+
+	return n.diskTaskCall("POST", target, data)
+}
+
+func (n *Node) CreateDirectory(name string, device string, filesystem string, addStorage bool) (*Task, error) {
+	err := validateDiskStorage(name, device)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filesystem {
+	case DIRECTORY_FS_EXT4, DIRECTORY_FS_XFS, "":
+	default:
+		return nil, errors.New(fmt.Sprintf("filesystem has wrong value. Posible values is: [%s|%s|empty]", DIRECTORY_FS_EXT4, DIRECTORY_FS_XFS))
+	}
+
+	target := "nodes/" + n.Node + "/disks/directory"
+
+	data := make(url.Values)
+	data.Add("name", name)
+	data.Add("device", device)
+	if len(filesystem) > 0 { data.Add("filesystem", filesystem) }
+	if addStorage { data.Add("add_storage", "1") }
+
+	return n.diskTaskCall("POST", target, data)
+}
+
+func (o *ZFSPoolOptions) Validate() error {
+	var errs ValidationErrors
+
+	if !diskStorageNameRegexp.MatchString(o.Name) {
+		errs.add("name", "wrong pool name: %s", o.Name)
+	}
+
+	minDevices := 1
+	switch o.RaidLevel {
+	case ZFS_RAID_SINGLE:
+	case ZFS_RAID_MIRROR:
+		minDevices = 2
+	case ZFS_RAID_RAIDZ:
+		minDevices = 3
+	case ZFS_RAID_RAID10, ZFS_RAID_RAIDZ2:
+		minDevices = 4
+	case ZFS_RAID_RAIDZ3:
+		minDevices = 5
+	default:
+		errs.add("raidlevel", "wrong value. Posible values is: [%s|%s|%s|%s|%s|%s]", ZFS_RAID_SINGLE, ZFS_RAID_MIRROR, ZFS_RAID_RAID10, ZFS_RAID_RAIDZ, ZFS_RAID_RAIDZ2, ZFS_RAID_RAIDZ3)
+	}
+
+	if len(o.Devices) < minDevices {
+		errs.add("devices", "%s needs at least %d devices", o.RaidLevel, minDevices)
+	}
+	if o.RaidLevel == ZFS_RAID_SINGLE && len(o.Devices) > 1 {
+		errs.add("devices", "%s needs exactly 1 device", o.RaidLevel)
+	}
+	if o.RaidLevel == ZFS_RAID_RAID10 && len(o.Devices) % 2 != 0 {
+		errs.add("devices", "%s needs an even number of devices", o.RaidLevel)
+	}
+	for _, d := range o.Devices {
+		if !diskDevPathRegexp.MatchString(d) {
+			errs.add("devices", "wrong device: %s", d)
+		}
+	}
+
+	if o.Ashift != 0 && (o.Ashift < 9 || o.Ashift > 16) {
+		errs.add("ashift", "wrong value: %d. It shuld be 9-16", o.Ashift)
+	}
+
+	switch o.Compression {
+	case ZFS_COMPRESSION_ON, ZFS_COMPRESSION_OFF, ZFS_COMPRESSION_LZ4, ZFS_COMPRESSION_ZSTD, ZFS_COMPRESSION_GZIP, ZFS_COMPRESSION_ZLE, ZFS_COMPRESSION_LZJB, "":
+	default:
+		errs.add("compression", "wrong value. Posible values is: [%s|%s|%s|%s|%s|%s|%s|empty]", ZFS_COMPRESSION_ON, ZFS_COMPRESSION_OFF, ZFS_COMPRESSION_LZ4, ZFS_COMPRESSION_ZSTD, ZFS_COMPRESSION_GZIP, ZFS_COMPRESSION_ZLE, ZFS_COMPRESSION_LZJB)
+	}
+
+	return errs.orNil()
+}
+
+func (n *Node) CreateZFSPool(opts ZFSPoolOptions) (*Task, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	target := "nodes/" + n.Node + "/disks/zfs"
+
+	data := make(url.Values)
+	data.Add("name", opts.Name)
+	data.Add("raidlevel", opts.RaidLevel)
+	data.Add("devices", strings.Join(opts.Devices, ","))
+	if opts.Ashift > 0 { data.Add("ashift", strconv.Itoa(opts.Ashift)) }
+	if len(opts.Compression) > 0 { data.Add("compression", opts.Compression) }
+	if opts.AddStorage { data.Add("add_storage", "1") }
+
+	return n.diskTaskCall("POST", target, data)
+}
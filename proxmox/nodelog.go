@@ -0,0 +1,203 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	NODE_JOURNAL_POLL_INTERVAL = 1 * time.Second
+)
+
+var syslogTimeRegexp = regexp.MustCompile("^\\d{4}-\\d{2}-\\d{2}( \\d{2}:\\d{2}(:\\d{2})?)?$")
+var serviceNameRegexp = regexp.MustCompile("^[A-Za-z0-9_@.\\-]+$")
+
+type NodeSyslogOptions struct {
+	Start int
+	Limit int
+	Since string		// YYYY-MM-DD[ HH:MM[:SS]]
+	Until string		// YYYY-MM-DD[ HH:MM[:SS]]
+	Service string
+}
+
+type NodeJournalOptions struct {
+	Since int64			// UNIX epoch
+	Until int64			// UNIX epoch
+	LastEntries int
+	StartCursor string
+	EndCursor string
+}
+
+type NodeJournal struct {
+	Lines []string
+	StartCursor string
+	EndCursor string
+}
+
+func (o *NodeSyslogOptions) Validate() error {
+	var errs ValidationErrors
+
+	if o.Start < 0 {
+		errs.add("start", "could not be negative")
+	}
+	if o.Limit < 0 {
+		errs.add("limit", "could not be negative")
+	}
+	if len(o.Since) > 0 && !syslogTimeRegexp.MatchString(o.Since) {
+		errs.add("since", "wrong time: %s. It shuld be YYYY-MM-DD[ HH:MM[:SS]]", o.Since)
+	}
+	if len(o.Until) > 0 && !syslogTimeRegexp.MatchString(o.Until) {
+		errs.add("until", "wrong time: %s. It shuld be YYYY-MM-DD[ HH:MM[:SS]]", o.Until)
+	}
+	if len(o.Service) > 0 && !serviceNameRegexp.MatchString(o.Service) {
+		errs.add("service", "wrong service name: %s", o.Service)
+	}
+
+	return errs.orNil()
+}
+
+func (o *NodeJournalOptions) Validate() error {
+	var errs ValidationErrors
+
+	if o.Since < 0 {
+		errs.add("since", "could not be negative")
+	}
+	if o.Until < 0 {
+		errs.add("until", "could not be negative")
+	}
+	if o.Since > 0 && o.Until > 0 && o.Until < o.Since {
+		errs.add("until", "could not be before since")
+	}
+	if o.LastEntries < 0 {
+		errs.add("lastentries", "could not be negative")
+	}
+
+	return errs.orNil()
+}
+
+func (n *Node) Syslog(opts NodeSyslogOptions) ([]TaskLogLine, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	target := "nodes/" + n.Node + "/syslog"
+
+	data := make(url.Values)
+	if opts.Start > 0 { data.Add("start", strconv.Itoa(opts.Start)) }
+	if opts.Limit > 0 { data.Add("limit", strconv.Itoa(opts.Limit)) }
+	if len(opts.Since) > 0 { data.Add("since", opts.Since) }
+	if len(opts.Until) > 0 { data.Add("until", opts.Until) }
+	if len(opts.Service) > 0 { data.Add("service", opts.Service) }
+
+	var syslog []TaskLogLine
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, data, &syslog, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return syslog, nil
+}
+
+func (n *Node) Journal(opts NodeJournalOptions) (*NodeJournal, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	target := "nodes/" + n.Node + "/journal"
+
+	data := make(url.Values)
+	if opts.Since > 0 { data.Add("since", strconv.FormatInt(opts.Since, 10)) }
+	if opts.Until > 0 { data.Add("until", strconv.FormatInt(opts.Until, 10)) }
+	if opts.LastEntries > 0 { data.Add("lastentries", strconv.Itoa(opts.LastEntries)) }
+	if len(opts.StartCursor) > 0 { data.Add("startcursor", opts.StartCursor) }
+	if len(opts.EndCursor) > 0 { data.Add("endcursor", opts.EndCursor) }
+
+	var lines []string
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, data, &lines, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return ParseJournal(lines), nil
+}
+
+// ParseJournal splits the journal API output. The first and the last lines
+// are the cursors of the first and the last returned entries.
+func ParseJournal(lines []string) *NodeJournal {
+	journal := NodeJournal{}
+
+	if len(lines) > 0 {
+		journal.StartCursor = lines[0]
+		lines = lines[1:]
+	}
+	if len(lines) > 0 {
+		journal.EndCursor = lines[len(lines)-1]
+		lines = lines[:len(lines)-1]
+	}
+
+	journal.Lines = lines
+
+	return &journal
+}
+
+// FollowJournal sends journal lines to the lines channel, polling for new
+// entries from the last received cursor, until ctx is cancelled.
+func (n *Node) FollowJournal(ctx context.Context, opts NodeJournalOptions, lines chan<- string) error {
+	return PollJournal(ctx, n.Journal, opts, NODE_JOURNAL_POLL_INTERVAL, lines)
+}
+
+// PollJournal is the polling loop behind FollowJournal, reading entries with fetch.
+func PollJournal(ctx context.Context, fetch func(NodeJournalOptions) (*NodeJournal, error), opts NodeJournalOptions, interval time.Duration, lines chan<- string) error {
+	opts.Until = 0
+	opts.EndCursor = ""
+
+	// A caller supplied start cursor points at an entry not sent yet.
+	resumed := false
+
+	for {
+		journal, err := fetch(opts)
+		if err != nil {
+			return err
+		}
+
+		newLines := journal.Lines
+		// Resuming from the last end cursor returns that entry first, it was already sent.
+		if resumed && len(newLines) > 0 {
+			newLines = newLines[1:]
+		}
+
+		for _, l := range newLines {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case lines <- l:
+			}
+		}
+
+		if len(journal.EndCursor) > 0 {
+			opts = NodeJournalOptions{StartCursor: journal.EndCursor}
+			resumed = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	NETWORK_TYPE_BRIDGE = "bridge"
+	NETWORK_TYPE_BOND = "bond"
+	NETWORK_TYPE_ETH = "eth"
+	NETWORK_TYPE_ALIAS = "alias"
+	NETWORK_TYPE_VLAN = "vlan"
+	NETWORK_TYPE_OVS_BRIDGE = "OVSBridge"
+	NETWORK_TYPE_OVS_BOND = "OVSBond"
+	NETWORK_TYPE_OVS_PORT = "OVSPort"
+	NETWORK_TYPE_OVS_INT_PORT = "OVSIntPort"
+	NETWORK_TYPE_ANY_BRIDGE = "any_bridge"
+	NETWORK_TYPE_ANY_LOCAL_BRIDGE = "any_local_bridge"
+
+	BOND_MODE_BALANCE_RR = "balance-rr"
+	BOND_MODE_ACTIVE_BACKUP = "active-backup"
+	BOND_MODE_BALANCE_XOR = "balance-xor"
+	BOND_MODE_BROADCAST = "broadcast"
+	BOND_MODE_8023AD = "802.3ad"
+	BOND_MODE_BALANCE_TLB = "balance-tlb"
+	BOND_MODE_BALANCE_ALB = "balance-alb"
+	BOND_MODE_BALANCE_SLB = "balance-slb"
+	BOND_MODE_LACP_BALANCE_SLB = "lacp-balance-slb"
+	BOND_MODE_LACP_BALANCE_TCP = "lacp-balance-tcp"
+)
+
+var networkTypes = []string{NETWORK_TYPE_BRIDGE, NETWORK_TYPE_BOND, NETWORK_TYPE_ETH, NETWORK_TYPE_ALIAS, NETWORK_TYPE_VLAN,
+	NETWORK_TYPE_OVS_BRIDGE, NETWORK_TYPE_OVS_BOND, NETWORK_TYPE_OVS_PORT, NETWORK_TYPE_OVS_INT_PORT}
+
+var bondModes = []string{BOND_MODE_BALANCE_RR, BOND_MODE_ACTIVE_BACKUP, BOND_MODE_BALANCE_XOR, BOND_MODE_BROADCAST, BOND_MODE_8023AD,
+	BOND_MODE_BALANCE_TLB, BOND_MODE_BALANCE_ALB, BOND_MODE_BALANCE_SLB, BOND_MODE_LACP_BALANCE_SLB, BOND_MODE_LACP_BALANCE_TCP}
+
+var bridgeVidsRegexp = regexp.MustCompile("^\\d+(-\\d+)?([ ,;]\\d+(-\\d+)?)*$")
+
+type NetworkInterface struct {
+	Iface string				`json:"iface"`
+	Type string					`json:"type"`
+	Method string				`json:"method"`
+	Method6 string				`json:"method6"`
+	Families []string			`json:"families"`
+	Active PVEBool				`json:"active"`
+	Exists PVEBool				`json:"exists"`
+	Autostart PVEBool			`json:"autostart"`
+	Priority PVEInt				`json:"priority"`
+	Comments string				`json:"comments"`
+	MTU PVEInt					`json:"mtu"`
+
+	CIDR string					`json:"cidr"`
+	Address string				`json:"address"`
+	Netmask string				`json:"netmask"`
+	Gateway string				`json:"gateway"`
+	CIDR6 string				`json:"cidr6"`
+	Address6 string				`json:"address6"`
+	Netmask6 string				`json:"netmask6"`
+	Gateway6 string				`json:"gateway6"`
+
+	// bridge
+	BridgePorts string			`json:"bridge_ports"`
+	BridgeVlanAware PVEBool		`json:"bridge_vlan_aware"`
+	BridgeVids string			`json:"bridge_vids"`
+
+	// bond
+	Slaves string				`json:"slaves"`
+	BondMode string				`json:"bond_mode"`
+	BondPrimary string			`json:"bond-primary"`
+	BondXmitHashPolicy string	`json:"bond_xmit_hash_policy"`
+
+	// vlan
+	VlanId PVEInt				`json:"vlan-id"`
+	VlanRawDevice string		`json:"vlan-raw-device"`
+
+	// OVS
+	OVSBridge string			`json:"ovs_bridge"`
+	OVSBonds string				`json:"ovs_bonds"`
+	OVSPorts string				`json:"ovs_ports"`
+	OVSOptions string			`json:"ovs_options"`
+	OVSTag PVEInt				`json:"ovs_tag"`
+}
+
+func (ni *NetworkInterface) Validate() error {
+	var errs ValidationErrors
+
+	if !ifaceNameRegexp.MatchString(ni.Iface) {
+		errs.add("iface", "wrong interface name: %s", ni.Iface)
+	}
+
+	knownType := false
+	for _, t := range networkTypes {
+		if ni.Type == t {
+			knownType = true
+		}
+	}
+	if !knownType {
+		errs.add("type", "wrong value. Posible values is: [%s]", strings.Join(networkTypes, "|"))
+	}
+
+	if ni.MTU != 0 && (ni.MTU < 1280 || ni.MTU > 65520) {
+		errs.add("mtu", "wrong value. It shuld be 1280-65520")
+	}
+
+	if len(ni.CIDR) > 0 {
+		if _, ok := parseCIDR(ni.CIDR, false); !ok {
+			errs.add("cidr", "wrong value. It shuld be IPv4/CIDR")
+		}
+	}
+
+	if len(ni.Gateway) > 0 {
+		if !isIPv4(ni.Gateway) {
+			errs.add("gateway", "wrong IPv4 address: %s", ni.Gateway)
+		} else if len(ni.CIDR) == 0 && len(ni.Address) == 0 {
+			errs.add("gateway", "could be set only with cidr")
+		}
+	}
+
+	if len(ni.CIDR6) > 0 {
+		if _, ok := parseCIDR(ni.CIDR6, true); !ok {
+			errs.add("cidr6", "wrong value. It shuld be IPv6/CIDR")
+		}
+	}
+
+	if len(ni.Gateway6) > 0 {
+		if !isIPv6(ni.Gateway6) {
+			errs.add("gateway6", "wrong IPv6 address: %s", ni.Gateway6)
+		} else if len(ni.CIDR6) == 0 && len(ni.Address6) == 0 {
+			errs.add("gateway6", "could be set only with cidr6")
+		}
+	}
+
+	for _, p := range strings.Fields(ni.BridgePorts) {
+		if !ifaceNameRegexp.MatchString(p) {
+			errs.add("bridge_ports", "wrong interface name: %s", p)
+		}
+		if p == ni.Iface {
+			errs.add("bridge_ports", "bridge could not be its own port")
+		}
+	}
+
+	if len(ni.BridgeVids) > 0 && !bridgeVidsRegexp.MatchString(ni.BridgeVids) {
+		errs.add("bridge_vids", "wrong value. It shuld be list of VLAN ids or ranges 2-4094")
+	}
+
+	if ni.Type == NETWORK_TYPE_BOND || ni.Type == NETWORK_TYPE_OVS_BOND {
+		slaves := strings.Fields(ni.Slaves)
+		if ni.Type == NETWORK_TYPE_OVS_BOND {
+			slaves = strings.Fields(ni.OVSBonds)
+		}
+		if len(slaves) == 0 {
+			errs.add("slaves", "could not be zero for bond")
+		}
+		for _, s := range slaves {
+			if !ifaceNameRegexp.MatchString(s) {
+				errs.add("slaves", "wrong interface name: %s", s)
+			}
+		}
+
+		knownMode := len(ni.BondMode) == 0
+		for _, m := range bondModes {
+			if ni.BondMode == m {
+				knownMode = true
+			}
+		}
+		if !knownMode {
+			errs.add("bond_mode", "wrong value. Posible values is: [%s]", strings.Join(bondModes, "|"))
+		}
+
+		switch ni.BondXmitHashPolicy {
+		case "":
+		case "layer2", "layer2+3", "layer3+4":
+			if ni.BondMode != BOND_MODE_BALANCE_XOR && ni.BondMode != BOND_MODE_8023AD {
+				errs.add("bond_xmit_hash_policy", "could be set only for %s and %s modes", BOND_MODE_BALANCE_XOR, BOND_MODE_8023AD)
+			}
+		default:
+			errs.add("bond_xmit_hash_policy", "wrong value. Posible values is: [layer2|layer2+3|layer3+4]")
+		}
+
+		if len(ni.BondPrimary) > 0 && ni.BondMode != BOND_MODE_ACTIVE_BACKUP {
+			errs.add("bond-primary", "could be set only for %s mode", BOND_MODE_ACTIVE_BACKUP)
+		}
+	} else if len(ni.Slaves) > 0 || len(ni.BondMode) > 0 {
+		errs.add("slaves", "could be set only for bond")
+	}
+
+	if ni.Type == NETWORK_TYPE_VLAN {
+		if ni.VlanId != 0 && (ni.VlanId < 1 || ni.VlanId > 4094) {
+			errs.add("vlan-id", "wrong value. It shuld be 1-4094")
+		}
+		if !strings.Contains(ni.Iface, ".") && (ni.VlanId == 0 || len(ni.VlanRawDevice) == 0) {
+			errs.add("vlan-raw-device", "vlan-id and vlan-raw-device are required if iface is not <device>.<vlan-id>")
+		}
+		if len(ni.VlanRawDevice) > 0 && !ifaceNameRegexp.MatchString(ni.VlanRawDevice) {
+			errs.add("vlan-raw-device", "wrong interface name: %s", ni.VlanRawDevice)
+		}
+	}
+
+	switch ni.Type {
+	case NETWORK_TYPE_OVS_BOND, NETWORK_TYPE_OVS_PORT, NETWORK_TYPE_OVS_INT_PORT:
+		if len(ni.OVSBridge) == 0 {
+			errs.add("ovs_bridge", "could not be zero for %s", ni.Type)
+		}
+	}
+
+	if ni.OVSTag != 0 && (ni.OVSTag < 1 || ni.OVSTag > 4094) {
+		errs.add("ovs_tag", "wrong VLAN tag. It shuld be 1-4094")
+	}
+
+	return errs.orNil()
+}
+
+func (ni *NetworkInterface) GetUrlDataValues() url.Values {
+	data := make(url.Values)
+
+	data.Add("iface", ni.Iface)
+	data.Add("type", ni.Type)
+	if ni.Autostart { data.Add("autostart", "1") }
+	if len(ni.Comments) > 0 { data.Add("comments", ni.Comments) }
+	if ni.MTU > 0 { data.Add("mtu", strconv.Itoa(int(ni.MTU))) }
+	if len(ni.CIDR) > 0 { data.Add("cidr", ni.CIDR) }
+	if len(ni.Address) > 0 { data.Add("address", ni.Address) }
+	if len(ni.Netmask) > 0 { data.Add("netmask", ni.Netmask) }
+	if len(ni.Gateway) > 0 { data.Add("gateway", ni.Gateway) }
+	if len(ni.CIDR6) > 0 { data.Add("cidr6", ni.CIDR6) }
+	if len(ni.Address6) > 0 { data.Add("address6", ni.Address6) }
+	if len(ni.Netmask6) > 0 { data.Add("netmask6", ni.Netmask6) }
+	if len(ni.Gateway6) > 0 { data.Add("gateway6", ni.Gateway6) }
+	if len(ni.BridgePorts) > 0 { data.Add("bridge_ports", ni.BridgePorts) }
+	if ni.BridgeVlanAware { data.Add("bridge_vlan_aware", "1") }
+	if len(ni.BridgeVids) > 0 { data.Add("bridge_vids", ni.BridgeVids) }
+	if len(ni.Slaves) > 0 { data.Add("slaves", ni.Slaves) }
+	if len(ni.BondMode) > 0 { data.Add("bond_mode", ni.BondMode) }
+	if len(ni.BondPrimary) > 0 { data.Add("bond-primary", ni.BondPrimary) }
+	if len(ni.BondXmitHashPolicy) > 0 { data.Add("bond_xmit_hash_policy", ni.BondXmitHashPolicy) }
+	if ni.VlanId > 0 { data.Add("vlan-id", strconv.Itoa(int(ni.VlanId))) }
+	if len(ni.VlanRawDevice) > 0 { data.Add("vlan-raw-device", ni.VlanRawDevice) }
+	if len(ni.OVSBridge) > 0 { data.Add("ovs_bridge", ni.OVSBridge) }
+	if len(ni.OVSBonds) > 0 { data.Add("ovs_bonds", ni.OVSBonds) }
+	if len(ni.OVSPorts) > 0 { data.Add("ovs_ports", ni.OVSPorts) }
+	if len(ni.OVSOptions) > 0 { data.Add("ovs_options", ni.OVSOptions) }
+	if ni.OVSTag > 0 { data.Add("ovs_tag", strconv.Itoa(int(ni.OVSTag))) }
+
+	return data
+}
+
+func (n *Node) GetNetworkInterfaces(ifaceType string) ([]NetworkInterface, error) {
+	target := "nodes/" + n.Node + "/network"
+
+	data := make(url.Values)
+	if len(ifaceType) > 0 { data.Add("type", ifaceType) }
+
+	var interfaces []NetworkInterface
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, data, &interfaces, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return interfaces, nil
+}
+
+func (n *Node) GetNetworkInterface(iface string) (*NetworkInterface, error) {
+	target := "nodes/" + n.Node + "/network/" + url.PathEscape(iface)
+
+	var networkInterface NetworkInterface
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, nil, &networkInterface, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	if len(networkInterface.Iface) == 0 {
+		networkInterface.Iface = iface
+	}
+
+	return &networkInterface, nil
+}
+
+func (n *Node) networkCall(method string, target string, data url.Values) error {
+	var result interface{}
+
+	httpCode, err := n.parent.(*Proxmox).APICall2(method, target, data, &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return nil
+}
+
+func (n *Node) CreateNetworkInterface(networkInterface NetworkInterface) error {
+	err := networkInterface.Validate()
+	if err != nil {
+		return err
+	}
+
+	return n.networkCall("POST", "nodes/" + n.Node + "/network", networkInterface.GetUrlDataValues())
+}
+
+func (n *Node) UpdateNetworkInterface(networkInterface NetworkInterface, delete []string) error {
+	err := networkInterface.Validate()
+	if err != nil {
+		return err
+	}
+
+	data := networkInterface.GetUrlDataValues()
+	data.Del("iface")
+	if len(delete) > 0 { data.Add("delete", strings.Join(delete, ",")) }
+
+	return n.networkCall("PUT", "nodes/" + n.Node + "/network/" + url.PathEscape(networkInterface.Iface), data)
+}
+
+func (n *Node) DeleteNetworkInterface(iface string) error {
+	if !ifaceNameRegexp.MatchString(iface) {
+		return errors.New(fmt.Sprintf("wrong interface name: %s", iface))
+	}
+
+	return n.networkCall("DELETE", "nodes/" + n.Node + "/network/" + url.PathEscape(iface), nil)
+}
+
+func (n *Node) ApplyNetworkChanges() (*Task, error) {
+	target := "nodes/" + n.Node + "/network"
+
+	var taskID TaskID
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("PUT", target, nil, &taskID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return n.GetTask(taskID), nil
+}
+
+func (n *Node) RevertNetworkChanges() error {
+	return n.networkCall("DELETE", "nodes/" + n.Node + "/network", nil)
+}
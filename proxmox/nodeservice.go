@@ -0,0 +1,133 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	SERVICE_STATE_RUNNING = "running"
+	SERVICE_STATE_STOPPED = "stopped"
+
+	SERVICE_ACTION_START = "start"
+	SERVICE_ACTION_STOP = "stop"
+	SERVICE_ACTION_RESTART = "restart"
+	SERVICE_ACTION_RELOAD = "reload"
+)
+
+var nodeServiceNames = []string{
+	"chrony",
+	"corosync",
+	"cron",
+	"ksmtuned",
+	"postfix",
+	"proxmox-firewall",
+	"pve-cluster",
+	"pve-firewall",
+	"pve-ha-crm",
+	"pve-ha-lrm",
+	"pvedaemon",
+	"pvefw-logger",
+	"pveproxy",
+	"pvescheduler",
+	"pvestatd",
+	"spiceproxy",
+	"sshd",
+	"syslog",
+	"systemd-journald",
+	"systemd-timesyncd",
+}
+
+type NodeService struct {
+	Service string		`json:"service"`
+	Name string			`json:"name"`
+	Desc string			`json:"desc"`
+	State string		`json:"state"`
+	ActiveState string	`json:"active-state"`
+	UnitState string	`json:"unit-state"`
+}
+
+func ValidateServiceName(service string) error {
+	for _, s := range nodeServiceNames {
+		if s == service {
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("service has wrong value. Posible values is: [%s]", strings.Join(nodeServiceNames, "|")))
+}
+
+func (n *Node) Services() ([]NodeService, error) {
+	target := "nodes/" + n.Node + "/services"
+
+	var services []NodeService
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, nil, &services, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return services, nil
+}
+
+func (n *Node) GetService(service string) (*NodeService, error) {
+	err := ValidateServiceName(service)
+	if err != nil {
+		return nil, err
+	}
+
+	target := "nodes/" + n.Node + "/services/" + service + "/state"
+
+	var nodeService NodeService
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, nil, &nodeService, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return &nodeService, nil
+}
+
+func (n *Node) serviceAction(service string, action string) (*Task, error) {
+	err := ValidateServiceName(service)
+	if err != nil {
+		return nil, err
+	}
+
+	target := "nodes/" + n.Node + "/services/" + service + "/" + action
+
+	var taskID TaskID
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("POST", target, nil, &taskID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return n.GetTask(taskID), nil
+}
+
+func (n *Node) StartService(service string) (*Task, error) {
+	return n.serviceAction(service, SERVICE_ACTION_START)
+}
+
+func (n *Node) StopService(service string) (*Task, error) {
+	return n.serviceAction(service, SERVICE_ACTION_STOP)
+}
+
+func (n *Node) RestartService(service string) (*Task, error) {
+	return n.serviceAction(service, SERVICE_ACTION_RESTART)
+}
+
+func (n *Node) ReloadService(service string) (*Task, error) {
+	return n.serviceAction(service, SERVICE_ACTION_RELOAD)
+}
@@ -0,0 +1,142 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+const (
+	RRD_TIMEFRAME_HOUR = "hour"
+	RRD_TIMEFRAME_DAY = "day"
+	RRD_TIMEFRAME_WEEK = "week"
+	RRD_TIMEFRAME_MONTH = "month"
+	RRD_TIMEFRAME_YEAR = "year"
+
+	RRD_CF_AVERAGE = "AVERAGE"
+	RRD_CF_MAX = "MAX"
+
+	NODE_BOOT_MODE_EFI = "efi"
+	NODE_BOOT_MODE_BIOS = "legacy-bios"
+)
+
+type NodeCPUInfo struct {
+	Model string		`json:"model"`
+	Cpus PVEInt			`json:"cpus"`
+	Sockets PVEInt		`json:"sockets"`
+	Cores PVEInt		`json:"cores"`
+	MHz PVEFloat		`json:"mhz"`
+	HVM PVEBool			`json:"hvm"`
+	UserHz PVEInt		`json:"user_hz"`
+	Flags string		`json:"flags"`
+}
+
+type NodeMemoryInfo struct {
+	Total PVEInt	`json:"total"`
+	Used PVEInt		`json:"used"`
+	Free PVEInt		`json:"free"`
+}
+
+type NodeFSInfo struct {
+	Total PVEInt	`json:"total"`
+	Used PVEInt		`json:"used"`
+	Free PVEInt		`json:"free"`
+	Avail PVEInt	`json:"avail"`
+}
+
+type NodeKSMInfo struct {
+	Shared PVEInt	`json:"shared"`
+}
+
+type NodeBootInfo struct {
+	Mode string			`json:"mode"`
+	SecureBoot PVEBool	`json:"secureboot"`
+}
+
+type NodeKernelInfo struct {
+	SysName string	`json:"sysname"`
+	Release string	`json:"release"`
+	Version string	`json:"version"`
+	Machine string	`json:"machine"`
+}
+
+type NodeStatus struct {
+	Cpu PVEFloat					`json:"cpu"`
+	Wait PVEFloat					`json:"wait"`
+	Idle PVEFloat					`json:"idle"`
+	Uptime PVEInt					`json:"uptime"`
+	LoadAvg []PVEFloat				`json:"loadavg"`
+	KVersion string					`json:"kversion"`
+	PVEVersion string				`json:"pveversion"`
+	CPUInfo NodeCPUInfo				`json:"cpuinfo"`
+	Memory NodeMemoryInfo			`json:"memory"`
+	Swap NodeMemoryInfo				`json:"swap"`
+	RootFS NodeFSInfo				`json:"rootfs"`
+	KSM NodeKSMInfo					`json:"ksm"`
+	BootInfo NodeBootInfo			`json:"boot-info"`
+	CurrentKernel NodeKernelInfo	`json:"current-kernel"`
+}
+
+type NodeRRDData struct {
+	Time PVEInt			`json:"time"`
+	Cpu PVEFloat		`json:"cpu"`
+	MaxCpu PVEFloat		`json:"maxcpu"`
+	IOWait PVEFloat		`json:"iowait"`
+	LoadAvg PVEFloat	`json:"loadavg"`
+	MemTotal PVEFloat	`json:"memtotal"`
+	MemUsed PVEFloat	`json:"memused"`
+	SwapTotal PVEFloat	`json:"swaptotal"`
+	SwapUsed PVEFloat	`json:"swapused"`
+	NetIn PVEFloat		`json:"netin"`
+	NetOut PVEFloat		`json:"netout"`
+	RootTotal PVEFloat	`json:"roottotal"`
+	RootUsed PVEFloat	`json:"rootused"`
+}
+
+func (n *Node) GetStatus() (*NodeStatus, error) {
+	target := "nodes/" + n.Node + "/status"
+
+	var nodeStatus NodeStatus
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, nil, &nodeStatus, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return &nodeStatus, nil
+}
+
+func (n *Node) GetRRDData(timeframe string, cf string) ([]NodeRRDData, error) {
+	switch timeframe {
+	case RRD_TIMEFRAME_HOUR, RRD_TIMEFRAME_DAY, RRD_TIMEFRAME_WEEK, RRD_TIMEFRAME_MONTH, RRD_TIMEFRAME_YEAR:
+	default:
+		return nil, errors.New(fmt.Sprintf("timeframe has wrong value. Posible values is: [%s|%s|%s|%s|%s]", RRD_TIMEFRAME_HOUR, RRD_TIMEFRAME_DAY, RRD_TIMEFRAME_WEEK, RRD_TIMEFRAME_MONTH, RRD_TIMEFRAME_YEAR))
+	}
+
+	switch cf {
+	case RRD_CF_AVERAGE, RRD_CF_MAX, "":
+	default:
+		return nil, errors.New(fmt.Sprintf("cf has wrong value. Posible values is: [%s|%s|empty]", RRD_CF_AVERAGE, RRD_CF_MAX))
+	}
+
+	target := "nodes/" + n.Node + "/rrddata"
+
+	data := make(url.Values)
+	data.Add("timeframe", timeframe)
+	if len(cf) > 0 { data.Add("cf", cf) }
+
+	var rrdData []NodeRRDData
+
+	httpCode, err := n.parent.(*Proxmox).APICall2("GET", target, data, &rrdData, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return rrdData, nil
+}
@@ -11,6 +11,8 @@ import (
 	"io/ioutil"
 	"encoding/json"
 	"reflect"
+	"sort"
+	"strconv"
 )
 
 const (
@@ -130,21 +132,73 @@ func (px *Proxmox) GetHost() string {
 	return px.host
 }
 
+type APIError struct {
+	Code int
+	Message string
+	Errors map[string]string
+}
+
+func (ae *APIError) Error() string {
+	str := fmt.Sprintf("HTTP Request return error: %d",ae.Code)
+	if len(ae.Message) > 0 {
+		str += ": " + ae.Message
+	}
+	var keys []string
+	for k := range ae.Errors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		str += fmt.Sprintf("; %s: %s", k, strings.TrimSpace(ae.Errors[k]))
+	}
+	return str
+}
+
+// IsAlreadyExists reports an API error about an existing object. Parameter
+// checks (like cluster/nextid) put the reason into the per-parameter errors.
+func IsAlreadyExists(err error) bool {
+	ae, ok := err.(*APIError)
+	if !ok {
+		return false
+	}
+	if strings.Contains(ae.Message, "already exists") {
+		return true
+	}
+	for _, e := range ae.Errors {
+		if strings.Contains(e, "already exists") {
+			return true
+		}
+	}
+	return false
+}
+
 func (px *Proxmox) APICall(method string, target APITarget, data url.Values) ([]byte,int,error){
+	responseBody, httpCode, _, err := px.apiCall(method, target, data)
+	return responseBody, httpCode, err
+}
+
+func (px *Proxmox) apiCall(method string, target APITarget, data url.Values) ([]byte,int,string,error){
 	if time.Since(px.ticketTime) >= time.Duration(API_TOKEN_LIFETIME - API_TOKEN_UPDATEBEFORE) {
 		err := px.updateTicket()
 		if err != nil {
-			return nil, 0, err
+			return nil, 0, "", err
 		}
 	}
 
-	request, err := http.NewRequest(method, string(target), strings.NewReader( data.Encode()))
+	var request *http.Request
+	var err error
+
+	if (method == "GET" || method == "DELETE") && len(data) > 0 {
+		request, err = http.NewRequest(method, string(target) + "?" + data.Encode(), nil)
+	} else {
+		request, err = http.NewRequest(method, string(target), strings.NewReader( data.Encode()))
+	}
 
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 
-	if method == "GET" || method == "DELETE" || method == "POST" {
+	if method == "GET" || method == "DELETE" || method == "POST" || method == "PUT" {
 		request.Header.Add("CSRFPreventionToken",px.csrftoken)
 	}
 
@@ -161,9 +215,9 @@ func (px *Proxmox) APICall(method string, target APITarget, data url.Values) ([]
 
 	if err != nil {
 		if response != nil {
-			return nil, response.StatusCode, err
+			return nil, response.StatusCode, response.Status, err
 		} else {
-			return nil, 0, err
+			return nil, 0, "", err
 		}
 	}
 
@@ -171,9 +225,9 @@ func (px *Proxmox) APICall(method string, target APITarget, data url.Values) ([]
 
 	responseBody, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return nil, response.StatusCode, err
+		return nil, response.StatusCode, response.Status, err
 	} else {
-		return responseBody, response.StatusCode, err
+		return responseBody, response.StatusCode, response.Status, err
 	}
 
 }
@@ -184,12 +238,24 @@ func (px *Proxmox) APICall2(method string, target string, data url.Values, resul
 		return 0, err
 	}
 
-	responseData, httpCode, err := px.APICall(method, apitarget, data)
+	responseData, httpCode, status, err := px.apiCall(method, apitarget, data)
 	if err != nil {
 		return 0, err
 	}
 	if httpCode != 200 {
-		return httpCode, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+		apiError := &APIError{
+			Code: httpCode,
+			Message: strings.TrimSpace(strings.TrimPrefix(status, strconv.Itoa(httpCode))),
+		}
+
+		var body struct {
+			Errors map[string]string `json:"errors"`
+		}
+		if json.Unmarshal(responseData, &body) == nil {
+			apiError.Errors = body.Errors
+		}
+
+		return httpCode, apiError
 	}
 
 
@@ -0,0 +1,923 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var qemuMachineRegexp = regexp.MustCompile("^(pc|pc(-i440fx)?-\\d+(\\.\\d+)+(\\+pve\\d+)?|q35|pc-q35-\\d+(\\.\\d+)+(\\+pve\\d+)?|virt(-\\d+(\\.\\d+)+)?)(,.*)?$")
+var qemuCpuTypeRegexp = regexp.MustCompile("^[a-zA-Z0-9_.\\-]+$")
+var qemuCpuFlagRegexp = regexp.MustCompile("^[+-][a-zA-Z0-9\\-_.]+$")
+var qemuPassthroughKeyRegexp = regexp.MustCompile("^(hostpci|usb)\\d+$")
+
+type QemuStatus struct {
+	HA HAStatus						`json:"ha"`
+	Agent PVEBool					`json:"agent"`
+	Balloon int64					`json:"balloon"`
+	BalloonInfo *QemuBalloonInfo	`json:"ballooninfo"`
+	RunningMachine string			`json:"running-machine"`
+	RunningQemu string				`json:"running-qemu"`
+	Spice PVEBool					`json:"spice"`
+	QemuBase
+}
+
+type Qemu struct {
+	Pid int64	`json:"pid"`
+	QemuBase
+	BasicObject
+}
+
+func (q *Qemu) statusAction(action string, data url.Values) (*Task, error) {
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/status/" + action
+
+	return q.taskCall("POST", target, data)
+}
+
+func skiplockData(skiplock bool) url.Values {
+	data := make(url.Values)
+	if skiplock {
+		data.Add("skiplock", "1")
+	}
+	return data
+}
+
+func (q *Qemu) taskCall(method string, target string, data url.Values) (*Task, error) {
+	var taskID TaskID
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2(method, target, data, &taskID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return q.parent.(*Node).GetTask(taskID), nil
+}
+
+func (q *Qemu) Start(skiplock bool) (*Task, error) {
+	return q.statusAction("start", skiplockData(skiplock))
+}
+
+func (q *Qemu) Stop(skiplock bool) (*Task, error) {
+	return q.statusAction("stop", skiplockData(skiplock))
+}
+
+func (q *Qemu) Shutdown(forceStop bool, timeout int) (*Task, error) {
+	data := make(url.Values)
+	if forceStop { data.Add("forceStop", "1") }
+	if timeout > 0 { data.Add("timeout", strconv.Itoa(timeout)) }
+
+	return q.statusAction("shutdown", data)
+}
+
+func (q *Qemu) Reboot(timeout int) (*Task, error) {
+	data := make(url.Values)
+	if timeout > 0 { data.Add("timeout", strconv.Itoa(timeout)) }
+
+	return q.statusAction("reboot", data)
+}
+
+func (q *Qemu) Reset(skiplock bool) (*Task, error) {
+	return q.statusAction("reset", skiplockData(skiplock))
+}
+
+func (q *Qemu) Suspend(toDisk bool, stateStorage string) (*Task, error) {
+	if len(stateStorage) > 0 && !toDisk {
+		return nil, errors.New("stateStorage could be set only with toDisk")
+	}
+
+	data := make(url.Values)
+	if toDisk { data.Add("todisk", "1") }
+	if len(stateStorage) > 0 { data.Add("statestorage", stateStorage) }
+
+	return q.statusAction("suspend", data)
+}
+
+func (q *Qemu) Resume(skiplock bool) (*Task, error) {
+	return q.statusAction("resume", skiplockData(skiplock))
+}
+
+func (q *Qemu) GetStatus() (*QemuStatus, error) {
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/status/current"
+
+	var qemuStatus QemuStatus
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("GET", target, nil, &qemuStatus, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return &qemuStatus, nil
+}
+
+func (q *Qemu) WaitForStatus(status string, timeout int) (bool, *QemuStatus, error) {
+	var qemuStatus *QemuStatus
+	var err error
+	t:=60
+	if timeout > 0 {
+		t = timeout
+	}
+	for i:=0; i <= t; i++ {
+		qemuStatus, err = q.GetStatus()
+		if err != nil { return false, nil, err }
+		if qemuStatus.Status == status {
+			return true, qemuStatus, nil
+		}
+		time.Sleep(1 * time.Second)
+	}
+
+	return false, qemuStatus, errors.New("timeout reached, status not get")
+}
+
+type QemuConfig struct {
+	CPU CPUConfig
+	Agent AgentConfig
+	BootOrder []string
+	EFIDisk *EFIDisk
+	TPMState *TPMState
+	CloudInit CloudInitConfig
+	Disks []QemuDisk
+	Networks []QemuNetwork
+	HostPCI []QemuHostPCI
+	USB []QemuUSB
+	Serials map[int]string
+	Unused map[int]string
+
+	BaseQemuConfig
+}
+
+func (q *Qemu) GetConfig() (*QemuConfig, error) {
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/config"
+
+	var qemuConfigReceiver QemuConfigReceiver
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("GET", target, nil, &qemuConfigReceiver, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	qemuConfig, err := qemuConfigReceiver.Parse()
+	if err != nil {
+		return nil, err
+	}
+	qemuConfig.VmId = q.VmId
+
+	return qemuConfig, nil
+}
+
+func (q *Qemu) UpdateConfig(qemuConfig QemuConfig, delete []string) error {
+	qemuConfig.VmId = q.VmId
+
+	err := qemuConfig.validate(false)
+	if err != nil {
+		return err
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/config"
+
+	data := qemuConfig.GetUrlDataValues()
+	for _, k := range []string{"vmid", "pool", "start"} {
+		data.Del(k)
+	}
+	if len(qemuConfig.Digest) > 0 { data.Add("digest", qemuConfig.Digest) }
+	if len(delete) > 0 { data.Add("delete", strings.Join(delete, ",")) }
+
+	var result interface{}
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("PUT", target, data, &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return nil
+}
+
+func (q *Qemu) Delete(purge bool, destroyUnreferenced bool) (*Task, error) {
+	return q.parent.(*Node).RemoveQemu(q.VmId, purge, destroyUnreferenced)
+}
+
+func (qcp *QemuConfig) Validate() error {
+	return qcp.validate(true)
+}
+
+func (qcp *QemuConfig) validate(create bool) error {
+	var errs ValidationErrors
+
+	if create && (qcp.VmId < VMID_MIN || qcp.VmId > VMID_MAX) {
+		errs.add("vmid", "wrong value. It shuld be %d-%d", VMID_MIN, VMID_MAX)
+	}
+
+	if len(qcp.Name) > 0 && !isDNSName(qcp.Name) {
+		errs.add("name", "wrong value. It shuld be a valid DNS name")
+	}
+
+	if len(qcp.Description) > 8192 {
+		errs.add("description", "too long. It shuld be 0-8192 bytes")
+	}
+
+	if qcp.Cores < 0 || qcp.Cores > 1024 {
+		errs.add("cores", "wrong value. It shuld be 1-1024")
+	}
+
+	if qcp.Sockets < 0 || qcp.Sockets > 16 {
+		errs.add("sockets", "wrong value. It shuld be 1-16")
+	}
+
+	if qcp.Vcpus < 0 || (qcp.Vcpus > 0 && int64(qcp.Vcpus) > int64(maxInt(qcp.Cores, 1) * maxInt(qcp.Sockets, 1))) {
+		errs.add("vcpus", "wrong value. It shuld be 1-(cores*sockets)")
+	}
+
+	if qcp.CpuLimit < 0 || qcp.CpuLimit > 128 {
+		errs.add("cpulimit", "wrong value. It shuld be 0-128")
+	}
+
+	if qcp.CpuUnits < 0 || qcp.CpuUnits > 262144 {
+		errs.add("cpuunits", "wrong value. It shuld be 1-262144")
+	}
+
+	if qcp.Memory != 0 && qcp.Memory < 16 {
+		errs.add("memory", "wrong value. It shuld be 16-N")
+	}
+
+	if qcp.Balloon < 0 || (qcp.Balloon > 0 && qcp.Memory > 0 && qcp.Balloon > qcp.Memory) {
+		errs.add("balloon", "wrong value. It shuld be 0-memory")
+	}
+
+	switch qcp.Bios {
+	case QEMU_BIOS_SEABIOS, QEMU_BIOS_OVMF, "":
+	default:
+		errs.add("bios", "wrong value. Posible values is: [%s|%s|empty]", QEMU_BIOS_SEABIOS, QEMU_BIOS_OVMF)
+	}
+
+	if len(qcp.Machine) > 0 && !qemuMachineRegexp.MatchString(qcp.Machine) {
+		errs.add("machine", "wrong value: %s", qcp.Machine)
+	}
+
+	switch qcp.OSType {
+	case "other", "wxp", "w2k", "w2k3", "w2k8", "wvista", "win7", "win8", "win10", "win11", "l24", "l26", "solaris", "":
+	default:
+		errs.add("ostype", "wrong value: %s", qcp.OSType)
+	}
+
+	switch qcp.ScsiHw {
+	case "lsi", "lsi53c810", "virtio-scsi-pci", "virtio-scsi-single", "megasas", "pvscsi", "":
+	default:
+		errs.add("scsihw", "wrong value: %s", qcp.ScsiHw)
+	}
+
+	if len(qcp.Pool) > 0 && !poolNameRegexp.MatchString(qcp.Pool) {
+		errs.add("pool", "wrong value: %s", qcp.Pool)
+	}
+
+	if len(qcp.Hookscript) > 0 && !hookscriptRegexp.MatchString(qcp.Hookscript) {
+		errs.add("hookscript", "wrong value. It shuld be <storage>:snippets/<file>")
+	}
+
+	for _, t := range parseTags(qcp.Tags) {
+		if !tagRegexp.MatchString(t) {
+			errs.add("tags", "wrong value: %s", t)
+		}
+	}
+
+	if len(qcp.CPU.Type) > 0 && !qemuCpuTypeRegexp.MatchString(qcp.CPU.Type) {
+		errs.add("cpu", "wrong cpu type: %s", qcp.CPU.Type)
+	}
+
+	for _, f := range qcp.CPU.Flags {
+		if !qemuCpuFlagRegexp.MatchString(f) {
+			errs.add("cpu", "wrong flag: %s. It shuld be +flag or -flag", f)
+		}
+	}
+
+	switch qcp.Agent.Type {
+	case "virtio", "isa", "":
+	default:
+		errs.add("agent", "wrong type. Posible values is: [virtio|isa|empty]")
+	}
+
+	if qcp.EFIDisk != nil {
+		if qcp.Bios != QEMU_BIOS_OVMF {
+			errs.add("efidisk0", "could be set only with bios %s", QEMU_BIOS_OVMF)
+		}
+		if len(qcp.EFIDisk.Volume) == 0 {
+			errs.add("efidisk0", "volume could not be zero")
+		}
+		switch qcp.EFIDisk.EfiType {
+		case "2m", "4m", "":
+		default:
+			errs.add("efidisk0", "wrong efitype. Posible values is: [2m|4m|empty]")
+		}
+	}
+
+	if qcp.TPMState != nil {
+		if len(qcp.TPMState.Volume) == 0 {
+			errs.add("tpmstate0", "volume could not be zero")
+		}
+		switch qcp.TPMState.Version {
+		case "v1.2", "v2.0", "":
+		default:
+			errs.add("tpmstate0", "wrong version. Posible values is: [v1.2|v2.0|empty]")
+		}
+	}
+
+	devices := make(map[string]bool)
+	for _, d := range qcp.Disks {
+		key := d.Key()
+		if devices[key] {
+			errs.add(key, "duplicate index")
+		}
+		devices[key] = true
+		d.validate(&errs)
+	}
+
+	for _, n := range qcp.Networks {
+		key := "net" + strconv.Itoa(n.Index)
+		if devices[key] {
+			errs.add(key, "duplicate index")
+		}
+		devices[key] = true
+		n.validate(key, &errs)
+	}
+
+	for _, b := range qcp.BootOrder {
+		if !devices[b] && !qemuPassthroughKeyRegexp.MatchString(b) {
+			errs.add("boot", "unknown device in boot order: %s", b)
+		}
+	}
+
+	for _, h := range qcp.HostPCI {
+		key := "hostpci" + strconv.Itoa(h.Index)
+		if devices[key] {
+			errs.add(key, "duplicate index")
+		}
+		devices[key] = true
+		h.validate(key, &errs)
+	}
+
+	for _, u := range qcp.USB {
+		key := "usb" + strconv.Itoa(u.Index)
+		if devices[key] {
+			errs.add(key, "duplicate index")
+		}
+		devices[key] = true
+		u.validate(key, &errs)
+	}
+
+	qcp.CloudInit.validate(&errs)
+
+	for _, ic := range qcp.CloudInit.IPConfigs {
+		if !devices["net" + strconv.Itoa(ic.Index)] && len(qcp.Networks) > 0 {
+			errs.add("ipconfig" + strconv.Itoa(ic.Index), "no matching net%d device", ic.Index)
+		}
+	}
+
+	for idx, s := range qcp.Serials {
+		key := "serial" + strconv.Itoa(idx)
+		if idx < 0 || idx >= QEMU_MAX_SERIAL {
+			errs.add(key, "wrong index. It shuld be 0-%d", QEMU_MAX_SERIAL-1)
+		}
+		if s != "socket" && !strings.HasPrefix(s, "/dev/") {
+			errs.add(key, "wrong value. It shuld be socket or /dev/...")
+		}
+	}
+
+	return errs.orNil()
+}
+
+func maxInt(v PVEInt, min int64) int64 {
+	if int64(v) < min {
+		return min
+	}
+	return int64(v)
+}
+
+func (qcp *QemuConfig) GetUrlDataValues() url.Values {
+	data := make(url.Values)
+
+	if qcp.VmId > 0 { data.Add("vmid", strconv.Itoa(int(qcp.VmId))) }
+	if len(qcp.Name) > 0 { data.Add("name", qcp.Name) }
+	if len(qcp.Description) > 0 { data.Add("description", qcp.Description) }
+	if qcp.Cores > 0 { data.Add("cores", strconv.Itoa(int(qcp.Cores))) }
+	if qcp.Sockets > 0 { data.Add("sockets", strconv.Itoa(int(qcp.Sockets))) }
+	if qcp.Vcpus > 0 { data.Add("vcpus", strconv.Itoa(int(qcp.Vcpus))) }
+	if qcp.CpuLimit > 0 { data.Add("cpulimit", strconv.FormatFloat(float64(qcp.CpuLimit), 'f', -1, 64)) }
+	if qcp.CpuUnits > 0 { data.Add("cpuunits", strconv.Itoa(int(qcp.CpuUnits))) }
+	if len(qcp.CPU.String()) > 0 { data.Add("cpu", qcp.CPU.String()) }
+	if qcp.Memory > 0 { data.Add("memory", strconv.Itoa(int(qcp.Memory))) }
+	if qcp.Balloon > 0 { data.Add("balloon", strconv.Itoa(int(qcp.Balloon))) }
+	if len(qcp.Bios) > 0 { data.Add("bios", qcp.Bios) }
+	if len(qcp.Machine) > 0 { data.Add("machine", qcp.Machine) }
+	if len(qcp.OSType) > 0 { data.Add("ostype", qcp.OSType) }
+	if len(qcp.ScsiHw) > 0 { data.Add("scsihw", qcp.ScsiHw) }
+	if len(qcp.Vga) > 0 { data.Add("vga", qcp.Vga) }
+	if qcp.Numa { data.Add("numa", "1") }
+	if qcp.OnBoot { data.Add("onboot", "1") }
+	if qcp.Protection { data.Add("protection", "1") }
+	if qcp.Template { data.Add("template", "1") }
+	if qcp.Tablet { data.Add("tablet", "1") }
+	if len(qcp.Hookscript) > 0 { data.Add("hookscript", qcp.Hookscript) }
+	if len(qcp.Tags) > 0 { data.Add("tags", qcp.Tags) }
+	if len(qcp.Pool) > 0 { data.Add("pool", qcp.Pool) }
+	if qcp.Start { data.Add("start", "1") }
+	if len(qcp.Agent.String()) > 0 { data.Add("agent", qcp.Agent.String()) }
+	if len(qcp.BootOrder) > 0 { data.Add("boot", "order=" + strings.Join(qcp.BootOrder, ";")) }
+	if qcp.EFIDisk != nil && len(qcp.EFIDisk.String()) > 0 { data.Add("efidisk0", qcp.EFIDisk.String()) }
+	if qcp.TPMState != nil && len(qcp.TPMState.String()) > 0 { data.Add("tpmstate0", qcp.TPMState.String()) }
+
+	qcp.CloudInit.addUrlDataValues(data)
+
+	for _, d := range qcp.Disks {
+		if len(d.String()) > 0 {
+			data.Add(d.Key(), d.String())
+		}
+	}
+
+	for _, n := range qcp.Networks {
+		if len(n.String()) > 0 {
+			data.Add("net" + strconv.Itoa(n.Index), n.String())
+		}
+	}
+
+	for _, h := range qcp.HostPCI {
+		if len(h.String()) > 0 {
+			data.Add("hostpci" + strconv.Itoa(h.Index), h.String())
+		}
+	}
+
+	for _, u := range qcp.USB {
+		if len(u.String()) > 0 {
+			data.Add("usb" + strconv.Itoa(u.Index), u.String())
+		}
+	}
+
+	for idx, s := range qcp.Serials {
+		data.Add("serial" + strconv.Itoa(idx), s)
+	}
+
+	return data
+}
+
+func (q *Qemu) RegenerateCloudInit() error {
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/cloudinit"
+
+	var result interface{}
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("PUT", target, nil, &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return nil
+}
+
+func (q *Qemu) DumpCloudInit(dumpType string) (string, error) {
+	switch dumpType {
+	case QEMU_CLOUDINIT_DUMP_USER, QEMU_CLOUDINIT_DUMP_NETWORK, QEMU_CLOUDINIT_DUMP_META:
+	default:
+		return "", errors.New(fmt.Sprintf("wrong dump type. Posible values is: [%s|%s|%s]", QEMU_CLOUDINIT_DUMP_USER, QEMU_CLOUDINIT_DUMP_NETWORK, QEMU_CLOUDINIT_DUMP_META))
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/cloudinit/dump"
+
+	data := make(url.Values)
+	data.Add("type", dumpType)
+
+	var result string
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("GET", target, data, &result, nil)
+	if err != nil {
+		return "", err
+	}
+	if httpCode != 200 {
+		return "", errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return result, nil
+}
+
+type QemuMigrateLocalDisk struct {
+	VolId string					`json:"volid"`
+	Size PVEInt						`json:"size"`
+	DriveName string				`json:"drivename"`
+	Cdrom PVEBool					`json:"cdrom"`
+	IsUnused PVEBool				`json:"is_unused"`
+	ReferencedInSnapshot PVEBool	`json:"referenced_in_snapshot"`
+	ReferencedInConfig PVEBool		`json:"referenced_in_config"`
+}
+
+type QemuMigrateNodeBlocker struct {
+	UnavailableStorages []string	`json:"unavailable_storages"`
+	UnavailableResources []string	`json:"unavailable-resources"`
+}
+
+type QemuMigratePreconditions struct {
+	Running PVEBool										`json:"running"`
+	AllowedNodes []string								`json:"allowed_nodes"`
+	NotAllowedNodes map[string]QemuMigrateNodeBlocker	`json:"not_allowed_nodes"`
+	LocalDisks []QemuMigrateLocalDisk					`json:"local_disks"`
+	LocalResources []string								`json:"local_resources"`
+	MappedResources []string							`json:"mapped-resources"`
+}
+
+type QemuMigrateOptions struct {
+	Online bool
+	WithLocalDisks bool
+	// Source storage to target storage. An empty key maps every source storage.
+	TargetStorage map[string]string
+	MigrationNetwork string
+	MigrationType string
+	BWLimit int
+	Force bool
+}
+
+func (q *Qemu) CheckMigrate(targetNode string) (*QemuMigratePreconditions, error) {
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/migrate"
+
+	data := make(url.Values)
+	if len(targetNode) > 0 { data.Add("target", targetNode) }
+
+	var preconditions QemuMigratePreconditions
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("GET", target, data, &preconditions, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return &preconditions, nil
+}
+
+func (mo *QemuMigrateOptions) validate() error {
+	var errs ValidationErrors
+
+	switch mo.MigrationType {
+	case "secure", "insecure", "":
+	default:
+		errs.add("migration_type", "wrong value. Posible values is: [secure|insecure|empty]")
+	}
+
+	if len(mo.MigrationNetwork) > 0 {
+		_, v4 := parseCIDR(mo.MigrationNetwork, false)
+		_, v6 := parseCIDR(mo.MigrationNetwork, true)
+		if !v4 && !v6 {
+			errs.add("migration_network", "wrong value. It shuld be CIDR")
+		}
+	}
+
+	if mo.BWLimit < 0 {
+		errs.add("bwlimit", "wrong value. It shuld be 0-N")
+	}
+
+	if len(mo.TargetStorage) > 0 && !mo.WithLocalDisks {
+		errs.add("targetstorage", "could be set only with-local-disks")
+	}
+
+	return errs.orNil()
+}
+
+func (mo *QemuMigrateOptions) targetStorage() string {
+	var res []string
+	for src, dst := range mo.TargetStorage {
+		if len(src) == 0 {
+			res = append(res, dst)
+		} else {
+			res = append(res, src + ":" + dst)
+		}
+	}
+	sort.Strings(res)
+	return strings.Join(res, ",")
+}
+
+// Check reports why the migration described by opts would be refused.
+// PVE fills allowed_nodes only for stopped guests, so the target node is
+// checked against it only when the guest is not running.
+func (p *QemuMigratePreconditions) Check(targetNode string, opts QemuMigrateOptions) error {
+	if !p.Running {
+		allowed := false
+		for _, n := range p.AllowedNodes {
+			if n == targetNode {
+				allowed = true
+			}
+		}
+		if !allowed {
+			msg := fmt.Sprintf("could not be migrated to node %s", targetNode)
+			if blocker, ok := p.NotAllowedNodes[targetNode]; ok && len(blocker.UnavailableStorages) > 0 {
+				msg += ", unavailable storages: " + strings.Join(blocker.UnavailableStorages, ", ")
+			}
+			return errors.New(msg)
+		}
+	}
+
+	if bool(p.Running) && !opts.Online {
+		return errors.New("is running, online migration required")
+	}
+
+	if len(p.LocalResources) > 0 && !opts.Force {
+		return errors.New(fmt.Sprintf("uses local resources: %s", strings.Join(p.LocalResources, ", ")))
+	}
+
+	if !opts.WithLocalDisks {
+		var disks []string
+		for _, d := range p.LocalDisks {
+			if !d.Cdrom {
+				disks = append(disks, d.VolId)
+			}
+		}
+		if len(disks) > 0 {
+			return errors.New(fmt.Sprintf("has local disks, with-local-disks required: %s", strings.Join(disks, ", ")))
+		}
+	}
+
+	return nil
+}
+
+func (q *Qemu) Migrate(targetNode string, opts QemuMigrateOptions) (*Task, error) {
+	if len(targetNode) == 0 {
+		return nil, errors.New("target node could not be zero")
+	}
+
+	err := opts.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if targetNode == q.parent.(*Node).Node {
+		return nil, errors.New(fmt.Sprintf("Qemu VMID: %d is already on node %s", q.VmId, targetNode))
+	}
+
+	preconditions, err := q.CheckMigrate(targetNode)
+	if err != nil {
+		return nil, err
+	}
+
+	err = preconditions.Check(targetNode, opts)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Qemu VMID: %d %s", q.VmId, err.Error()))
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/migrate"
+
+	data := make(url.Values)
+	data.Add("target", targetNode)
+	if opts.Online { data.Add("online", "1") }
+	if opts.WithLocalDisks { data.Add("with-local-disks", "1") }
+	if len(opts.TargetStorage) > 0 { data.Add("targetstorage", opts.targetStorage()) }
+	if len(opts.MigrationNetwork) > 0 { data.Add("migration_network", opts.MigrationNetwork) }
+	if len(opts.MigrationType) > 0 { data.Add("migration_type", opts.MigrationType) }
+	if opts.BWLimit > 0 { data.Add("bwlimit", strconv.Itoa(opts.BWLimit)) }
+	if opts.Force { data.Add("force", "1") }
+
+	return q.taskCall("POST", target, data)
+}
+
+type QemuCloneOptions struct {
+	NewId int64
+	Name string
+	Description string
+	Full bool
+	TargetNode string
+	Storage string
+	Format string
+	Pool string
+	SnapName string
+	BWLimit int
+}
+
+func (co *QemuCloneOptions) validate() error {
+	var errs ValidationErrors
+
+	if co.NewId < VMID_MIN || co.NewId > VMID_MAX {
+		errs.add("newid", "wrong value. It shuld be %d-%d", VMID_MIN, VMID_MAX)
+	}
+
+	if len(co.Name) > 0 && !isDNSName(co.Name) {
+		errs.add("name", "wrong value. It shuld be a valid DNS name")
+	}
+
+	switch co.Format {
+	case "raw", "qcow2", "vmdk", "":
+	default:
+		errs.add("format", "wrong value. Posible values is: [raw|qcow2|vmdk|empty]")
+	}
+
+	if !co.Full && len(co.Storage) > 0 {
+		errs.add("storage", "could not be set for linked clone")
+	}
+
+	if !co.Full && len(co.Format) > 0 {
+		errs.add("format", "could not be set for linked clone")
+	}
+
+	if len(co.Pool) > 0 && !poolNameRegexp.MatchString(co.Pool) {
+		errs.add("pool", "wrong value: %s", co.Pool)
+	}
+
+	if co.BWLimit < 0 {
+		errs.add("bwlimit", "wrong value. It shuld be 0-N")
+	}
+
+	return errs.orNil()
+}
+
+func (q *Qemu) CheckFeature(feature string, snapshot string) (bool, error) {
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/feature"
+
+	guestFeature, err := getGuestFeature(q.parent.(*Node), target, feature, snapshot)
+	if err != nil {
+		return false, err
+	}
+
+	return bool(guestFeature.HasFeature), nil
+}
+
+func (q *Qemu) CanLinkedClone(snapname string) (bool, []string, error) {
+	qemuConfig, err := q.GetConfig()
+	if err != nil {
+		return false, nil, err
+	}
+
+	if !qemuConfig.Template {
+		return false, nil, nil
+	}
+
+	hasFeature, err := q.CheckFeature(QEMU_FEATURE_CLONE, snapname)
+	if err != nil {
+		return false, nil, err
+	}
+	if hasFeature {
+		return true, nil, nil
+	}
+
+	if len(snapname) > 0 {
+		qemuConfig, err = q.GetSnapshotConfig(snapname)
+		if err != nil {
+			return false, nil, err
+		}
+	}
+
+	blockers, err := q.featureBlockers(QEMU_FEATURE_CLONE, qemuConfig)
+	if err != nil {
+		return false, nil, err
+	}
+
+	return false, blockers, nil
+}
+
+func (q *Qemu) featureBlockers(feature string, qemuConfig *QemuConfig) ([]string, error) {
+	volumes := make(map[string]string)
+	for _, d := range qemuConfig.Disks {
+		if d.Media != "cdrom" {
+			volumes[d.Key()] = d.Volume
+		}
+	}
+	if qemuConfig.EFIDisk != nil {
+		volumes["efidisk0"] = qemuConfig.EFIDisk.Volume
+	}
+	if qemuConfig.TPMState != nil {
+		volumes["tpmstate0"] = qemuConfig.TPMState.Volume
+	}
+
+	return guestFeatureBlockers(q.parent.(*Node), feature, volumes)
+}
+
+func (q *Qemu) Clone(opts QemuCloneOptions) (*Task, error) {
+	err := opts.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Full {
+		hasFeature, err := q.CheckFeature(QEMU_FEATURE_COPY, opts.SnapName)
+		if err != nil {
+			return nil, err
+		}
+		if !hasFeature {
+			return nil, errors.New(fmt.Sprintf("Qemu VMID: %d does not support full clone", q.VmId))
+		}
+	} else {
+		ok, blockers, err := q.CanLinkedClone(opts.SnapName)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if len(blockers) > 0 {
+				return nil, errors.New(fmt.Sprintf("Qemu VMID: %d does not support linked clone, blocked by volumes: %s", q.VmId, strings.Join(blockers, ", ")))
+			}
+			return nil, errors.New(fmt.Sprintf("Qemu VMID: %d does not support linked clone, it shuld be a template on storage with clone support", q.VmId))
+		}
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/clone"
+
+	data := make(url.Values)
+	data.Add("newid", strconv.Itoa(int(opts.NewId)))
+	if len(opts.Name) > 0 { data.Add("name", opts.Name) }
+	if len(opts.Description) > 0 { data.Add("description", opts.Description) }
+	if opts.Full { data.Add("full", "1") } else { data.Add("full", "0") }
+	if len(opts.TargetNode) > 0 { data.Add("target", opts.TargetNode) }
+	if len(opts.Storage) > 0 { data.Add("storage", opts.Storage) }
+	if len(opts.Format) > 0 { data.Add("format", opts.Format) }
+	if len(opts.Pool) > 0 { data.Add("pool", opts.Pool) }
+	if len(opts.SnapName) > 0 { data.Add("snapname", opts.SnapName) }
+	if opts.BWLimit > 0 { data.Add("bwlimit", strconv.Itoa(opts.BWLimit)) }
+
+	return q.taskCall("POST", target, data)
+}
+
+func (q *Qemu) ConvertToTemplate(disk string) (*Task, error) {
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/template"
+
+	data := make(url.Values)
+	if len(disk) > 0 { data.Add("disk", disk) }
+
+	return q.taskCall("POST", target, data)
+}
+
+func (q *Qemu) GetVmId() int64 {
+	return q.VmId
+}
+
+func (q *Qemu) GetName() string {
+	return q.Name
+}
+
+func (q *Qemu) GetType() string {
+	return GUEST_TYPE_QEMU
+}
+
+func (q *Qemu) GetTags() []string {
+	return parseTags(q.Tags)
+}
+
+func (q *Qemu) StartGuest(skiplock bool) (*Task, error) {
+	return q.Start(skiplock)
+}
+
+func (q *Qemu) StopGuest(skiplock bool) (*Task, error) {
+	return q.Stop(skiplock)
+}
+
+func (q *Qemu) ShutdownGuest(forceStop bool, timeout int) (*Task, error) {
+	return q.Shutdown(forceStop, timeout)
+}
+
+func (q *Qemu) SetTags(tags []string) error {
+	digest, err := q.GetConfigDigest()
+	if err != nil {
+		return err
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/config"
+
+	data := make(url.Values)
+	data.Add("digest", digest)
+	if len(tags) > 0 { data.Add("tags", strings.Join(tags, ";")) } else { data.Add("delete", "tags") }
+
+	var result interface{}
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("PUT", target, data, &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	q.Tags = strings.Join(tags, ";")
+
+	return nil
+}
+
+func (q *Qemu) GetGuestStatus() (string, error) {
+	qemuStatus, err := q.GetStatus()
+	if err != nil {
+		return "", err
+	}
+
+	return qemuStatus.Status, nil
+}
+
+func (q *Qemu) MigrateTo(targetNode string, online bool) (*Task, error) {
+	return q.Migrate(targetNode, QemuMigrateOptions{Online: online})
+}
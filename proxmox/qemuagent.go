@@ -0,0 +1,298 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	QEMU_AGENT_EXEC_POLL_INTERVAL = 500 * time.Millisecond
+
+	QEMU_AGENT_FSFREEZE_THAWED = "thawed"
+	QEMU_AGENT_FSFREEZE_FROZEN = "frozen"
+)
+
+type QemuAgent struct {
+	qemu *Qemu
+}
+
+type QemuAgentExecStatus struct {
+	Exited PVEBool			`json:"exited"`
+	ExitCode PVEInt			`json:"exitcode"`
+	Signal PVEInt			`json:"signal"`
+	OutData string			`json:"out-data"`
+	ErrData string			`json:"err-data"`
+	OutTruncated PVEBool	`json:"out-truncated"`
+	ErrTruncated PVEBool	`json:"err-truncated"`
+}
+
+type QemuAgentFile struct {
+	Content string		`json:"content"`
+	Truncated PVEBool	`json:"truncated"`
+	BytesRead PVEInt	`json:"bytes-read"`
+}
+
+type QemuAgentIPAddress struct {
+	IPAddressType string	`json:"ip-address-type"`
+	IPAddress string		`json:"ip-address"`
+	Prefix PVEInt			`json:"prefix"`
+}
+
+type QemuAgentInterfaceStatistics struct {
+	RxBytes PVEInt		`json:"rx-bytes"`
+	RxPackets PVEInt	`json:"rx-packets"`
+	RxErrs PVEInt		`json:"rx-errs"`
+	RxDropped PVEInt	`json:"rx-dropped"`
+	TxBytes PVEInt		`json:"tx-bytes"`
+	TxPackets PVEInt	`json:"tx-packets"`
+	TxErrs PVEInt		`json:"tx-errs"`
+	TxDropped PVEInt	`json:"tx-dropped"`
+}
+
+type QemuAgentNetworkInterface struct {
+	Name string									`json:"name"`
+	HardwareAddress string						`json:"hardware-address"`
+	IPAddresses []QemuAgentIPAddress			`json:"ip-addresses"`
+	Statistics *QemuAgentInterfaceStatistics	`json:"statistics"`
+}
+
+type QemuAgentOSInfo struct {
+	ID string				`json:"id"`
+	Name string				`json:"name"`
+	PrettyName string		`json:"pretty-name"`
+	Version string			`json:"version"`
+	VersionID string		`json:"version-id"`
+	Variant string			`json:"variant"`
+	VariantID string		`json:"variant-id"`
+	KernelRelease string	`json:"kernel-release"`
+	KernelVersion string	`json:"kernel-version"`
+	Machine string			`json:"machine"`
+}
+
+type QemuAgentFsDisk struct {
+	Dev string			`json:"dev"`
+	Serial string		`json:"serial"`
+	BusType string		`json:"bus-type"`
+	Bus PVEInt			`json:"bus"`
+	Target PVEInt		`json:"target"`
+	Unit PVEInt			`json:"unit"`
+}
+
+type QemuAgentFsInfo struct {
+	Name string				`json:"name"`
+	Mountpoint string		`json:"mountpoint"`
+	Type string				`json:"type"`
+	TotalBytes PVEInt		`json:"total-bytes"`
+	UsedBytes PVEInt		`json:"used-bytes"`
+	Disk []QemuAgentFsDisk	`json:"disk"`
+}
+
+func (q *Qemu) Agent() *QemuAgent {
+	return &QemuAgent{qemu: q}
+}
+
+func (qa *QemuAgent) call(method string, command string, data url.Values, result interface{}) error {
+	target := "nodes/" + qa.qemu.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(qa.qemu.VmId)) + "/agent/" + command
+
+	httpCode, err := qa.qemu.parent.(*Node).parent.(*Proxmox).APICall2(method, target, data, result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return nil
+}
+
+func (qa *QemuAgent) Ping() error {
+	var result interface{}
+	return qa.call("POST", "ping", nil, &result)
+}
+
+func (qa *QemuAgent) Exec(command []string, inputData string) (int64, error) {
+	if len(command) == 0 {
+		return 0, errors.New("command could not be zero")
+	}
+
+	data := make(url.Values)
+	for _, c := range command {
+		data.Add("command", c)
+	}
+	if len(inputData) > 0 { data.Add("input-data", inputData) }
+
+	var result struct {
+		Pid PVEInt `json:"pid"`
+	}
+
+	err := qa.call("POST", "exec", data, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(result.Pid), nil
+}
+
+func (qa *QemuAgent) ExecStatus(pid int64) (*QemuAgentExecStatus, error) {
+	data := make(url.Values)
+	data.Add("pid", strconv.Itoa(int(pid)))
+
+	var execStatus QemuAgentExecStatus
+
+	err := qa.call("GET", "exec-status", data, &execStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	return &execStatus, nil
+}
+
+func (qa *QemuAgent) Run(ctx context.Context, command ...string) (*QemuAgentExecStatus, error) {
+	return qa.RunWithInput(ctx, "", command...)
+}
+
+func (qa *QemuAgent) RunWithInput(ctx context.Context, inputData string, command ...string) (*QemuAgentExecStatus, error) {
+	pid, err := qa.Exec(command, inputData)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		execStatus, err := qa.ExecStatus(pid)
+		if err != nil {
+			return nil, err
+		}
+		if execStatus.Exited {
+			return execStatus, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return execStatus, ctx.Err()
+		case <-time.After(QEMU_AGENT_EXEC_POLL_INTERVAL):
+		}
+	}
+}
+
+func (qa *QemuAgent) FileRead(file string) (*QemuAgentFile, error) {
+	data := make(url.Values)
+	data.Add("file", file)
+
+	var agentFile QemuAgentFile
+
+	err := qa.call("GET", "file-read", data, &agentFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &agentFile, nil
+}
+
+func (qa *QemuAgent) FileWrite(file string, content []byte) error {
+	data := make(url.Values)
+	data.Add("file", file)
+	data.Add("content", base64.StdEncoding.EncodeToString(content))
+	data.Add("encode", "0")
+
+	var result interface{}
+
+	return qa.call("POST", "file-write", data, &result)
+}
+
+func (qa *QemuAgent) GetNetworkInterfaces() ([]QemuAgentNetworkInterface, error) {
+	var result struct {
+		Result []QemuAgentNetworkInterface `json:"result"`
+	}
+
+	err := qa.call("GET", "network-get-interfaces", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Result, nil
+}
+
+func (qa *QemuAgent) GetOSInfo() (*QemuAgentOSInfo, error) {
+	var result struct {
+		Result QemuAgentOSInfo `json:"result"`
+	}
+
+	err := qa.call("GET", "get-osinfo", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.Result, nil
+}
+
+func (qa *QemuAgent) GetFsInfo() ([]QemuAgentFsInfo, error) {
+	var result struct {
+		Result []QemuAgentFsInfo `json:"result"`
+	}
+
+	err := qa.call("GET", "get-fsinfo", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Result, nil
+}
+
+func (qa *QemuAgent) FsFreezeFreeze() (int64, error) {
+	var result struct {
+		Result PVEInt `json:"result"`
+	}
+
+	err := qa.call("POST", "fsfreeze-freeze", nil, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(result.Result), nil
+}
+
+func (qa *QemuAgent) FsFreezeThaw() (int64, error) {
+	var result struct {
+		Result PVEInt `json:"result"`
+	}
+
+	err := qa.call("POST", "fsfreeze-thaw", nil, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(result.Result), nil
+}
+
+func (qa *QemuAgent) FsFreezeStatus() (string, error) {
+	var result struct {
+		Result string `json:"result"`
+	}
+
+	err := qa.call("POST", "fsfreeze-status", nil, &result)
+	if err != nil {
+		return "", err
+	}
+
+	return result.Result, nil
+}
+
+func (qa *QemuAgent) SetUserPassword(username string, password string, crypted bool) error {
+	if len(password) < 5 || len(password) > 1024 {
+		return errors.New("wrong password length. It shuld be 5-1024")
+	}
+
+	data := make(url.Values)
+	data.Add("username", username)
+	data.Add("password", password)
+	if crypted { data.Add("crypted", "1") }
+
+	var result interface{}
+
+	return qa.call("POST", "set-user-password", data, &result)
+}
@@ -0,0 +1,1032 @@
+package proxmox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	QEMU_STATUS_RUNNING = "running"
+	QEMU_STATUS_STOPPED = "stopped"
+
+	QEMU_QMPSTATUS_RUNNING = "running"
+	QEMU_QMPSTATUS_PAUSED = "paused"
+	QEMU_QMPSTATUS_SUSPENDED = "suspended"
+	QEMU_QMPSTATUS_PRELAUNCH = "prelaunch"
+
+	QEMU_FEATURE_SNAPSHOT = GUEST_FEATURE_SNAPSHOT
+	QEMU_FEATURE_CLONE = GUEST_FEATURE_CLONE
+	QEMU_FEATURE_COPY = GUEST_FEATURE_COPY
+)
+
+type QemuBase struct {
+	Cpu float64			`json:"cpu"`
+	Cpus int			`json:"cpus"`
+	Disk int64			`json:"disk"`
+	DiskRead int64		`json:"diskread"`
+	DiskWrite int64		`json:"diskwrite"`
+	Lock string			`json:"lock"`
+	MaxDisk int64		`json:"maxdisk"`
+	MaxMem int64		`json:"maxmem"`
+	Mem int64			`json:"mem"`
+	Name string			`json:"name"`
+	NetIn int64			`json:"netin"`
+	NetOut int64		`json:"netout"`
+	QmpStatus string	`json:"qmpstatus"`
+	Status string		`json:"status"`
+	Tags string			`json:"tags"`
+	Template PVEBool	`json:"template"`
+	Uptime int64		`json:"uptime"`
+	VmId int64			`json:"vmid"`
+}
+
+type QemuBalloonInfo struct {
+	Actual int64			`json:"actual"`
+	FreeMem int64			`json:"free_mem"`
+	LastUpdate int64		`json:"last_update"`
+	MajorPageFaults int64	`json:"major_page_faults"`
+	MaxMem int64			`json:"max_mem"`
+	MemSwappedIn int64		`json:"mem_swapped_in"`
+	MemSwappedOut int64		`json:"mem_swapped_out"`
+	MinorPageFaults int64	`json:"minor_page_faults"`
+	TotalMem int64			`json:"total_mem"`
+}
+
+type HAStatus struct {
+	Managed PVEBool	`json:"managed"`
+	State string	`json:"state"`
+	Group string	`json:"group"`
+}
+
+const (
+	QEMU_BUS_SCSI = "scsi"
+	QEMU_BUS_VIRTIO = "virtio"
+	QEMU_BUS_SATA = "sata"
+	QEMU_BUS_IDE = "ide"
+
+	QEMU_BIOS_SEABIOS = "seabios"
+	QEMU_BIOS_OVMF = "ovmf"
+
+	QEMU_NET_MODEL_VIRTIO = "virtio"
+	QEMU_NET_MODEL_E1000 = "e1000"
+	QEMU_NET_MODEL_RTL8139 = "rtl8139"
+	QEMU_NET_MODEL_VMXNET3 = "vmxnet3"
+	QEMU_NET_MODEL_E1000E = "e1000e"
+	QEMU_NET_MODEL_E1000_82540EM = "e1000-82540em"
+	QEMU_NET_MODEL_E1000_82544GC = "e1000-82544gc"
+	QEMU_NET_MODEL_E1000_82545EM = "e1000-82545em"
+	QEMU_NET_MODEL_I82551 = "i82551"
+	QEMU_NET_MODEL_I82557B = "i82557b"
+	QEMU_NET_MODEL_I82559ER = "i82559er"
+	QEMU_NET_MODEL_NE2K_ISA = "ne2k_isa"
+	QEMU_NET_MODEL_NE2K_PCI = "ne2k_pci"
+	QEMU_NET_MODEL_PCNET = "pcnet"
+
+	QEMU_MAX_NETWORKS = 32
+	QEMU_MAX_HOSTPCI = 16
+	QEMU_MAX_USB = 14
+	QEMU_MAX_SERIAL = 4
+
+	QEMU_CITYPE_NOCLOUD = "nocloud"
+	QEMU_CITYPE_CONFIGDRIVE2 = "configdrive2"
+	QEMU_CITYPE_OPENNEBULA = "opennebula"
+
+	QEMU_CLOUDINIT_DUMP_USER = "user"
+	QEMU_CLOUDINIT_DUMP_NETWORK = "network"
+	QEMU_CLOUDINIT_DUMP_META = "meta"
+)
+
+var qemuIndexedKeyRegexp = regexp.MustCompile("^(scsi|virtio|sata|ide|net|ipconfig|hostpci|usb|serial|unused)(\\d+)$")
+var qemuHostPCIRegexp = regexp.MustCompile("^([a-f0-9]{4,}:)?[a-f0-9]{2}:[a-f0-9]{2}(\\.[0-7])?(;([a-f0-9]{4,}:)?[a-f0-9]{2}:[a-f0-9]{2}(\\.[0-7])?)*$")
+var qemuUSBHostRegexp = regexp.MustCompile("^(0x)?[a-fA-F0-9]{4}:(0x)?[a-fA-F0-9]{4}$|^\\d+-\\d+(\\.\\d+)*$")
+
+var qemuBusMaxDevices = map[string]int{
+	QEMU_BUS_SCSI: 31,
+	QEMU_BUS_VIRTIO: 16,
+	QEMU_BUS_SATA: 6,
+	QEMU_BUS_IDE: 4,
+}
+
+// PVE $nic_model_list
+var qemuNetModels = []string{
+	QEMU_NET_MODEL_RTL8139,
+	QEMU_NET_MODEL_NE2K_PCI,
+	QEMU_NET_MODEL_E1000,
+	QEMU_NET_MODEL_PCNET,
+	QEMU_NET_MODEL_VIRTIO,
+	QEMU_NET_MODEL_NE2K_ISA,
+	QEMU_NET_MODEL_I82551,
+	QEMU_NET_MODEL_I82557B,
+	QEMU_NET_MODEL_I82559ER,
+	QEMU_NET_MODEL_VMXNET3,
+	QEMU_NET_MODEL_E1000_82540EM,
+	QEMU_NET_MODEL_E1000_82544GC,
+	QEMU_NET_MODEL_E1000_82545EM,
+	QEMU_NET_MODEL_E1000E,
+}
+
+func isQemuNetModel(model string) bool {
+	for _, m := range qemuNetModels {
+		if model == m {
+			return true
+		}
+	}
+	return false
+}
+
+type CPUConfig struct {
+	Type string
+	Flags []string
+	Hidden bool
+}
+
+type AgentConfig struct {
+	Enabled bool
+	FsTrimClonedDisks bool
+	Type string
+}
+
+type EFIDisk struct {
+	Volume string
+	Format string
+	EfiType string
+	PreEnrolledKeys bool
+	Size string
+}
+
+type TPMState struct {
+	Volume string
+	Version string
+	Size string
+}
+
+type QemuDisk struct {
+	Bus string
+	Index int
+	Volume string
+	Media string
+	Cache string
+	Format string
+	Size string
+	Serial string
+	Discard bool
+	IOThread bool
+	SSD bool
+	ReadOnly bool
+	NoBackup bool
+}
+
+type QemuNetwork struct {
+	Index int
+	Model string
+	MACAddr string
+	Bridge string
+	Tag int
+	Trunks string
+	Firewall bool
+	LinkDown bool
+	Rate float64
+	Queues int
+	MTU int
+}
+
+type QemuHostPCI struct {
+	Index int
+	Host string
+	Mapping string
+	MDev string
+	PCIe bool
+	XVga bool
+	RomBar bool
+}
+
+type QemuUSB struct {
+	Index int
+	Host string
+	Mapping string
+	USB3 bool
+}
+
+type BaseQemuConfig struct {
+	Name string			`json:"name"`
+	Description string	`json:"description"`
+	Cores PVEInt		`json:"cores"`
+	Sockets PVEInt		`json:"sockets"`
+	Vcpus PVEInt		`json:"vcpus"`
+	CpuLimit PVEFloat	`json:"cpulimit"`
+	CpuUnits PVEInt		`json:"cpuunits"`
+	Memory PVEInt		`json:"memory"`
+	Balloon PVEInt		`json:"balloon"`
+	Bios string			`json:"bios"`
+	Machine string		`json:"machine"`
+	OSType string		`json:"ostype"`
+	ScsiHw string		`json:"scsihw"`
+	Vga string			`json:"vga"`
+	Numa PVEBool		`json:"numa"`
+	OnBoot PVEBool		`json:"onboot"`
+	Protection PVEBool	`json:"protection"`
+	Template PVEBool	`json:"template"`
+	Tablet PVEBool		`json:"tablet"`
+	Hookscript string	`json:"hookscript"`
+	Tags string			`json:"tags"`
+	Lock string			`json:"lock"`
+	Digest string		`json:"digest"`
+	Pool string			`json:"pool"`
+	Start PVEBool		`json:"start"`
+	VmId int64			`json:"vmid"`
+}
+
+type QemuConfigReceiver map[string]interface{}
+
+func (cc *CPUConfig) SetFromString(str string) {
+	if len(str) > 0 {
+		keypairs := parseKeyPairs(str)
+		for _, kp := range keypairs {
+			if len(kp) == 2 {
+				switch kp[0] {
+				case "cputype":
+					cc.Type = kp[1]
+				case "flags":
+					cc.Flags = strings.Split(kp[1], ";")
+				case "hidden":
+					h,_ := strconv.Atoi(kp[1])
+					cc.Hidden = h == 1
+				}
+			}
+			if len(kp) == 1 {
+				cc.Type = kp[0]
+			}
+		}
+	}
+}
+
+func (cc *CPUConfig) String() (string) {
+	var res []string
+
+	if len(cc.Type) > 0 { res = append(res,cc.Type) }
+	if len(cc.Flags) > 0 { res = append(res,fmt.Sprintf("flags=%s",strings.Join(cc.Flags,";"))) }
+	if cc.Hidden { res = append(res,"hidden=1") }
+
+	return strings.Join(res,",")
+}
+
+func (ac *AgentConfig) SetFromString(str string) {
+	if len(str) > 0 {
+		keypairs := parseKeyPairs(str)
+		for _, kp := range keypairs {
+			if len(kp) == 2 {
+				switch kp[0] {
+				case "enabled":
+					e,_ := strconv.Atoi(kp[1])
+					ac.Enabled = e == 1
+				case "fstrim_cloned_disks":
+					f,_ := strconv.Atoi(kp[1])
+					ac.FsTrimClonedDisks = f == 1
+				case "type":
+					ac.Type = kp[1]
+				}
+			}
+			if len(kp) == 1 {
+				e,_ := strconv.Atoi(kp[0])
+				ac.Enabled = e == 1
+			}
+		}
+	}
+}
+
+func (ac *AgentConfig) String() (string) {
+	var res []string
+
+	if !ac.Enabled && !ac.FsTrimClonedDisks && len(ac.Type) == 0 { return "" }
+
+	if ac.Enabled { res = append(res,"enabled=1") } else { res = append(res,"enabled=0") }
+	if ac.FsTrimClonedDisks { res = append(res,"fstrim_cloned_disks=1") }
+	if len(ac.Type) > 0 { res = append(res,fmt.Sprintf("type=%s",ac.Type)) }
+
+	return strings.Join(res,",")
+}
+
+func (ed *EFIDisk) SetFromString(str string) {
+	if len(str) > 0 {
+		keypairs := parseKeyPairs(str)
+		for _, kp := range keypairs {
+			if len(kp) == 2 {
+				switch kp[0] {
+				case "file":
+					ed.Volume = kp[1]
+				case "format":
+					ed.Format = kp[1]
+				case "efitype":
+					ed.EfiType = kp[1]
+				case "pre-enrolled-keys":
+					p,_ := strconv.Atoi(kp[1])
+					ed.PreEnrolledKeys = p == 1
+				case "size":
+					ed.Size = kp[1]
+				}
+			}
+			if len(kp) == 1 {
+				ed.Volume = kp[0]
+			}
+		}
+	}
+}
+
+func (ed *EFIDisk) String() (string) {
+	var res []string
+
+	if len(ed.Volume) == 0 { return "" }
+
+	res = append(res,ed.Volume)
+	if len(ed.Format) > 0 { res = append(res,fmt.Sprintf("format=%s",ed.Format)) }
+	if len(ed.EfiType) > 0 { res = append(res,fmt.Sprintf("efitype=%s",ed.EfiType)) }
+	if ed.PreEnrolledKeys { res = append(res,"pre-enrolled-keys=1") }
+	if len(ed.Size) > 0 { res = append(res,fmt.Sprintf("size=%s",ed.Size)) }
+
+	return strings.Join(res,",")
+}
+
+func (ts *TPMState) SetFromString(str string) {
+	if len(str) > 0 {
+		keypairs := parseKeyPairs(str)
+		for _, kp := range keypairs {
+			if len(kp) == 2 {
+				switch kp[0] {
+				case "file":
+					ts.Volume = kp[1]
+				case "version":
+					ts.Version = kp[1]
+				case "size":
+					ts.Size = kp[1]
+				}
+			}
+			if len(kp) == 1 {
+				ts.Volume = kp[0]
+			}
+		}
+	}
+}
+
+func (ts *TPMState) String() (string) {
+	var res []string
+
+	if len(ts.Volume) == 0 { return "" }
+
+	res = append(res,ts.Volume)
+	if len(ts.Version) > 0 { res = append(res,fmt.Sprintf("version=%s",ts.Version)) }
+	if len(ts.Size) > 0 { res = append(res,fmt.Sprintf("size=%s",ts.Size)) }
+
+	return strings.Join(res,",")
+}
+
+func (qd *QemuDisk) Key() string {
+	return qd.Bus + strconv.Itoa(qd.Index)
+}
+
+func (qd *QemuDisk) SetFromString(bus string, idx int, str string) {
+	qd.Bus = bus
+	qd.Index = idx
+	if len(str) > 0 {
+		keypairs := parseKeyPairs(str)
+		for _, kp := range keypairs {
+			if len(kp) == 2 {
+				switch kp[0] {
+				case "file":
+					qd.Volume = kp[1]
+				case "media":
+					qd.Media = kp[1]
+				case "cache":
+					qd.Cache = kp[1]
+				case "format":
+					qd.Format = kp[1]
+				case "size":
+					qd.Size = kp[1]
+				case "serial":
+					qd.Serial = kp[1]
+				case "discard":
+					qd.Discard = kp[1] == "on"
+				case "iothread":
+					i,_ := strconv.Atoi(kp[1])
+					qd.IOThread = i == 1
+				case "ssd":
+					s,_ := strconv.Atoi(kp[1])
+					qd.SSD = s == 1
+				case "ro":
+					ro,_ := strconv.Atoi(kp[1])
+					qd.ReadOnly = ro == 1
+				case "backup":
+					b,_ := strconv.Atoi(kp[1])
+					qd.NoBackup = b == 0
+				}
+			}
+			if len(kp) == 1 {
+				qd.Volume = kp[0]
+			}
+		}
+	}
+}
+
+func (qd *QemuDisk) String() (string) {
+	var res []string
+
+	if len(qd.Volume) == 0 { return "" }
+
+	res = append(res,qd.Volume)
+	if len(qd.Media) > 0 { res = append(res,fmt.Sprintf("media=%s",qd.Media)) }
+	if len(qd.Cache) > 0 { res = append(res,fmt.Sprintf("cache=%s",qd.Cache)) }
+	if len(qd.Format) > 0 { res = append(res,fmt.Sprintf("format=%s",qd.Format)) }
+	if len(qd.Size) > 0 { res = append(res,fmt.Sprintf("size=%s",qd.Size)) }
+	if len(qd.Serial) > 0 { res = append(res,fmt.Sprintf("serial=%s",qd.Serial)) }
+	if qd.Discard { res = append(res,"discard=on") }
+	if qd.IOThread { res = append(res,"iothread=1") }
+	if qd.SSD { res = append(res,"ssd=1") }
+	if qd.ReadOnly { res = append(res,"ro=1") }
+	if qd.NoBackup { res = append(res,"backup=0") }
+
+	return strings.Join(res,",")
+}
+
+func (qn *QemuNetwork) SetFromString(idx int, str string) {
+	qn.Index = idx
+	if len(str) > 0 {
+		keypairs := parseKeyPairs(str)
+		for _, kp := range keypairs {
+			if len(kp) == 2 && isQemuNetModel(kp[0]) {
+				qn.Model = kp[0]
+				qn.MACAddr = kp[1]
+				continue
+			}
+			if len(kp) == 2 {
+				switch kp[0] {
+				case "model":
+					qn.Model = kp[1]
+				case "macaddr":
+					qn.MACAddr = kp[1]
+				case "bridge":
+					qn.Bridge = kp[1]
+				case "tag":
+					qn.Tag,_ = strconv.Atoi(kp[1])
+				case "trunks":
+					qn.Trunks = kp[1]
+				case "firewall":
+					f,_ := strconv.Atoi(kp[1])
+					qn.Firewall = f == 1
+				case "link_down":
+					l,_ := strconv.Atoi(kp[1])
+					qn.LinkDown = l == 1
+				case "rate":
+					qn.Rate,_ = strconv.ParseFloat(kp[1], 64)
+				case "queues":
+					qn.Queues,_ = strconv.Atoi(kp[1])
+				case "mtu":
+					qn.MTU,_ = strconv.Atoi(kp[1])
+				}
+			}
+			if len(kp) == 1 {
+				qn.Model = kp[0]
+			}
+		}
+	}
+}
+
+func (qn *QemuNetwork) String() (string) {
+	var res []string
+
+	if len(qn.Model) == 0 { return "" }
+
+	if len(qn.MACAddr) > 0 {
+		res = append(res,fmt.Sprintf("%s=%s",qn.Model,qn.MACAddr))
+	} else {
+		res = append(res,qn.Model)
+	}
+	if len(qn.Bridge) > 0 { res = append(res,fmt.Sprintf("bridge=%s",qn.Bridge)) }
+	if qn.Tag > 0 { res = append(res,fmt.Sprintf("tag=%d",qn.Tag)) }
+	if len(qn.Trunks) > 0 { res = append(res,fmt.Sprintf("trunks=%s",qn.Trunks)) }
+	if qn.Firewall { res = append(res,"firewall=1") }
+	if qn.LinkDown { res = append(res,"link_down=1") }
+	if qn.Rate > 0 { res = append(res,fmt.Sprintf("rate=%s",strconv.FormatFloat(qn.Rate,'f',-1,64))) }
+	if qn.Queues > 0 { res = append(res,fmt.Sprintf("queues=%d",qn.Queues)) }
+	if qn.MTU > 0 { res = append(res,fmt.Sprintf("mtu=%d",qn.MTU)) }
+
+	return strings.Join(res,",")
+}
+
+func (hp *QemuHostPCI) SetFromString(idx int, str string) {
+	hp.Index = idx
+	hp.RomBar = true
+	if len(str) > 0 {
+		keypairs := parseKeyPairs(str)
+		for _, kp := range keypairs {
+			if len(kp) == 2 {
+				switch kp[0] {
+				case "host":
+					hp.Host = kp[1]
+				case "mapping":
+					hp.Mapping = kp[1]
+				case "mdev":
+					hp.MDev = kp[1]
+				case "pcie":
+					p,_ := strconv.Atoi(kp[1])
+					hp.PCIe = p == 1
+				case "x-vga":
+					x,_ := strconv.Atoi(kp[1])
+					hp.XVga = x == 1
+				case "rombar":
+					r,_ := strconv.Atoi(kp[1])
+					hp.RomBar = r == 1
+				}
+			}
+			if len(kp) == 1 {
+				hp.Host = kp[0]
+			}
+		}
+	}
+}
+
+func (hp *QemuHostPCI) String() (string) {
+	var res []string
+
+	switch {
+	case len(hp.Host) > 0:
+		res = append(res,hp.Host)
+	case len(hp.Mapping) > 0:
+		res = append(res,fmt.Sprintf("mapping=%s",hp.Mapping))
+	default:
+		return ""
+	}
+	if len(hp.MDev) > 0 { res = append(res,fmt.Sprintf("mdev=%s",hp.MDev)) }
+	if hp.PCIe { res = append(res,"pcie=1") }
+	if hp.XVga { res = append(res,"x-vga=1") }
+	if !hp.RomBar { res = append(res,"rombar=0") }
+
+	return strings.Join(res,",")
+}
+
+func (qu *QemuUSB) SetFromString(idx int, str string) {
+	qu.Index = idx
+	if len(str) > 0 {
+		keypairs := parseKeyPairs(str)
+		for _, kp := range keypairs {
+			if len(kp) == 2 {
+				switch kp[0] {
+				case "host":
+					qu.Host = kp[1]
+				case "mapping":
+					qu.Mapping = kp[1]
+				case "usb3":
+					u,_ := strconv.Atoi(kp[1])
+					qu.USB3 = u == 1
+				}
+			}
+			if len(kp) == 1 {
+				qu.Host = kp[0]
+			}
+		}
+	}
+}
+
+func (qu *QemuUSB) String() (string) {
+	var res []string
+
+	switch {
+	case len(qu.Host) > 0:
+		res = append(res,fmt.Sprintf("host=%s",qu.Host))
+	case len(qu.Mapping) > 0:
+		res = append(res,fmt.Sprintf("mapping=%s",qu.Mapping))
+	default:
+		return ""
+	}
+	if qu.USB3 { res = append(res,"usb3=1") }
+
+	return strings.Join(res,",")
+}
+
+func (qcr QemuConfigReceiver) Parse() (*QemuConfig, error) {
+	var qemuConfig QemuConfig
+
+	temp, err := json.Marshal(qcr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(temp, &qemuConfig.BaseQemuConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for k := range qcr {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		si, sj := qemuIndexedKeyRegexp.FindStringSubmatch(keys[i]), qemuIndexedKeyRegexp.FindStringSubmatch(keys[j])
+		if si == nil || sj == nil || si[1] != sj[1] {
+			return keys[i] < keys[j]
+		}
+		ii, _ := strconv.Atoi(si[2])
+		ij, _ := strconv.Atoi(sj[2])
+		return ii < ij
+	})
+
+	for _, k := range keys {
+		val := fmt.Sprintf("%v", qcr[k])
+
+		switch k {
+		case "cpu":
+			qemuConfig.CPU.SetFromString(val)
+		case "agent":
+			qemuConfig.Agent.SetFromString(val)
+		case "boot":
+			for _, kp := range parseKeyPairs(val) {
+				if len(kp) == 2 && kp[0] == "order" {
+					qemuConfig.BootOrder = strings.Split(kp[1], ";")
+				}
+			}
+		case "efidisk0":
+			qemuConfig.EFIDisk = &EFIDisk{}
+			qemuConfig.EFIDisk.SetFromString(val)
+		case "tpmstate0":
+			qemuConfig.TPMState = &TPMState{}
+			qemuConfig.TPMState.SetFromString(val)
+		case "ciuser":
+			qemuConfig.CloudInit.User = val
+		case "cipassword":
+			if strings.Trim(val, "*") != "" {
+				qemuConfig.CloudInit.Password = val
+			}
+		case "citype":
+			qemuConfig.CloudInit.Type = val
+		case "ciupgrade":
+			qemuConfig.CloudInit.Upgrade = val == "1"
+		case "sshkeys":
+			qemuConfig.CloudInit.SSHKeys = parseSSHKeys(val)
+		case "nameserver":
+			qemuConfig.CloudInit.Nameservers = strings.Fields(val)
+		case "searchdomain":
+			qemuConfig.CloudInit.SearchDomains = strings.Fields(val)
+		case "cicustom":
+			qemuConfig.CloudInit.Custom.SetFromString(val)
+		}
+
+		sm := qemuIndexedKeyRegexp.FindStringSubmatch(k)
+		if sm == nil {
+			continue
+		}
+		idx, _ := strconv.Atoi(sm[2])
+
+		switch sm[1] {
+		case "net":
+			qn := QemuNetwork{}
+			qn.SetFromString(idx, val)
+			qemuConfig.Networks = append(qemuConfig.Networks, qn)
+		case "ipconfig":
+			ic := QemuIPConfig{}
+			ic.SetFromString(idx, val)
+			qemuConfig.CloudInit.IPConfigs = append(qemuConfig.CloudInit.IPConfigs, ic)
+		case "hostpci":
+			hp := QemuHostPCI{}
+			hp.SetFromString(idx, val)
+			qemuConfig.HostPCI = append(qemuConfig.HostPCI, hp)
+		case "usb":
+			qu := QemuUSB{}
+			qu.SetFromString(idx, val)
+			qemuConfig.USB = append(qemuConfig.USB, qu)
+		case "serial":
+			if qemuConfig.Serials == nil {
+				qemuConfig.Serials = make(map[int]string)
+			}
+			qemuConfig.Serials[idx] = val
+		case "unused":
+			if qemuConfig.Unused == nil {
+				qemuConfig.Unused = make(map[int]string)
+			}
+			qemuConfig.Unused[idx] = val
+		default:
+			qd := QemuDisk{}
+			qd.SetFromString(sm[1], idx, val)
+			qemuConfig.Disks = append(qemuConfig.Disks, qd)
+		}
+	}
+
+	return &qemuConfig, nil
+}
+
+func (qd *QemuDisk) validate(errs *ValidationErrors) {
+	key := qd.Key()
+
+	max, ok := qemuBusMaxDevices[qd.Bus]
+	if !ok {
+		errs.add(key, "wrong bus. Posible values is: [%s|%s|%s|%s]", QEMU_BUS_SCSI, QEMU_BUS_VIRTIO, QEMU_BUS_SATA, QEMU_BUS_IDE)
+		return
+	}
+
+	if qd.Index < 0 || qd.Index >= max {
+		errs.add(key, "wrong index. It shuld be 0-%d", max-1)
+	}
+
+	if len(qd.Volume) == 0 {
+		errs.add(key, "volume could not be zero")
+	}
+
+	switch qd.Media {
+	case "disk", "cdrom", "":
+	default:
+		errs.add(key, "wrong media. Posible values is: [disk|cdrom|empty]")
+	}
+
+	switch qd.Cache {
+	case "none", "writethrough", "writeback", "unsafe", "directsync", "":
+	default:
+		errs.add(key, "wrong cache. Posible values is: [none|writethrough|writeback|unsafe|directsync|empty]")
+	}
+
+	switch qd.Format {
+	case "raw", "qcow2", "vmdk", "cow", "qed", "cloop", "":
+	default:
+		errs.add(key, "wrong format: %s", qd.Format)
+	}
+
+	if qd.IOThread && qd.Bus != QEMU_BUS_SCSI && qd.Bus != QEMU_BUS_VIRTIO {
+		errs.add(key, "iothread could be set only for %s and %s disks", QEMU_BUS_SCSI, QEMU_BUS_VIRTIO)
+	}
+
+	if qd.SSD && qd.Bus == QEMU_BUS_VIRTIO {
+		errs.add(key, "ssd could not be set for %s disks", QEMU_BUS_VIRTIO)
+	}
+}
+
+func (qn *QemuNetwork) validate(key string, errs *ValidationErrors) {
+	if qn.Index < 0 || qn.Index >= QEMU_MAX_NETWORKS {
+		errs.add(key, "wrong index. It shuld be 0-%d", QEMU_MAX_NETWORKS-1)
+	}
+
+	if !isQemuNetModel(qn.Model) {
+		errs.add(key + ".model", "wrong value. Posible values is: [%s]", strings.Join(qemuNetModels, "|"))
+	}
+
+	if len(qn.MACAddr) > 0 && !macAddrRegexp.MatchString(qn.MACAddr) {
+		errs.add(key + ".macaddr", "wrong unicast MAC address: %s", qn.MACAddr)
+	}
+
+	if len(qn.Bridge) > 0 && !ifaceNameRegexp.MatchString(qn.Bridge) {
+		errs.add(key + ".bridge", "wrong bridge name: %s", qn.Bridge)
+	}
+
+	if qn.Tag != 0 && (qn.Tag < 1 || qn.Tag > 4094) {
+		errs.add(key + ".tag", "wrong VLAN tag. It shuld be 1-4094")
+	}
+
+	if qn.Rate < 0 {
+		errs.add(key + ".rate", "wrong value. It shuld be 0-N")
+	}
+
+	if qn.Queues < 0 || qn.Queues > 64 {
+		errs.add(key + ".queues", "wrong value. It shuld be 0-64")
+	}
+
+	if qn.MTU != 0 && (qn.MTU < 1 || qn.MTU > 65520) {
+		errs.add(key + ".mtu", "wrong value. It shuld be 1-65520")
+	}
+}
+
+func (hp *QemuHostPCI) validate(key string, errs *ValidationErrors) {
+	if hp.Index < 0 || hp.Index >= QEMU_MAX_HOSTPCI {
+		errs.add(key, "wrong index. It shuld be 0-%d", QEMU_MAX_HOSTPCI-1)
+	}
+
+	if len(hp.Host) == 0 && len(hp.Mapping) == 0 {
+		errs.add(key, "host or mapping could not be zero")
+	}
+
+	if len(hp.Host) > 0 && !qemuHostPCIRegexp.MatchString(hp.Host) {
+		errs.add(key, "wrong host PCI id: %s", hp.Host)
+	}
+}
+
+func (qu *QemuUSB) validate(key string, errs *ValidationErrors) {
+	if qu.Index < 0 || qu.Index >= QEMU_MAX_USB {
+		errs.add(key, "wrong index. It shuld be 0-%d", QEMU_MAX_USB-1)
+	}
+
+	if len(qu.Host) == 0 && len(qu.Mapping) == 0 {
+		errs.add(key, "host or mapping could not be zero")
+	}
+
+	if len(qu.Host) > 0 && qu.Host != "spice" && !qemuUSBHostRegexp.MatchString(qu.Host) {
+		errs.add(key, "wrong host USB device: %s", qu.Host)
+	}
+}
+
+type QemuIPConfig struct {
+	Index int
+	IP string
+	GW string
+	IP6 string
+	GW6 string
+}
+
+func (ic *QemuIPConfig) SetFromString(idx int, str string) {
+	ic.Index = idx
+	if len(str) > 0 {
+		keypairs := parseKeyPairs(str)
+		for _, kp := range keypairs {
+			if len(kp) == 2 {
+				switch kp[0] {
+				case "ip":
+					ic.IP = kp[1]
+				case "gw":
+					ic.GW = kp[1]
+				case "ip6":
+					ic.IP6 = kp[1]
+				case "gw6":
+					ic.GW6 = kp[1]
+				}
+			}
+		}
+	}
+}
+
+func (ic *QemuIPConfig) String() (string) {
+	var res []string
+
+	if len(ic.IP) > 0 { res = append(res,fmt.Sprintf("ip=%s",ic.IP)) }
+	if len(ic.GW) > 0 { res = append(res,fmt.Sprintf("gw=%s",ic.GW)) }
+	if len(ic.IP6) > 0 { res = append(res,fmt.Sprintf("ip6=%s",ic.IP6)) }
+	if len(ic.GW6) > 0 { res = append(res,fmt.Sprintf("gw6=%s",ic.GW6)) }
+
+	return strings.Join(res,",")
+}
+
+func (ic *QemuIPConfig) validate(key string, errs *ValidationErrors) {
+	if ic.Index < 0 || ic.Index >= QEMU_MAX_NETWORKS {
+		errs.add(key, "wrong index. It shuld be 0-%d", QEMU_MAX_NETWORKS-1)
+	}
+
+	if len(ic.IP) > 0 && ic.IP != "dhcp" {
+		if _, ok := parseCIDR(ic.IP, false); !ok {
+			errs.add(key + ".ip", "wrong value. It shuld be dhcp or IPv4/CIDR")
+		}
+	}
+
+	if len(ic.GW) > 0 {
+		if !isIPv4(ic.GW) {
+			errs.add(key + ".gw", "wrong IPv4 address: %s", ic.GW)
+		} else if ic.IP == "dhcp" {
+			errs.add(key + ".gw", "could not be set with ip=dhcp")
+		}
+	}
+
+	if len(ic.IP6) > 0 && ic.IP6 != "dhcp" && ic.IP6 != "auto" {
+		if _, ok := parseCIDR(ic.IP6, true); !ok {
+			errs.add(key + ".ip6", "wrong value. It shuld be dhcp, auto or IPv6/CIDR")
+		}
+	}
+
+	if len(ic.GW6) > 0 {
+		if !isIPv6(ic.GW6) {
+			errs.add(key + ".gw6", "wrong IPv6 address: %s", ic.GW6)
+		} else if ic.IP6 == "dhcp" || ic.IP6 == "auto" {
+			errs.add(key + ".gw6", "could not be set with ip6=%s", ic.IP6)
+		}
+	}
+}
+
+type CloudInitCustom struct {
+	User string
+	Network string
+	Meta string
+	Vendor string
+}
+
+func (cc *CloudInitCustom) SetFromString(str string) {
+	if len(str) > 0 {
+		keypairs := parseKeyPairs(str)
+		for _, kp := range keypairs {
+			if len(kp) == 2 {
+				switch kp[0] {
+				case "user":
+					cc.User = kp[1]
+				case "network":
+					cc.Network = kp[1]
+				case "meta":
+					cc.Meta = kp[1]
+				case "vendor":
+					cc.Vendor = kp[1]
+				}
+			}
+		}
+	}
+}
+
+func (cc *CloudInitCustom) String() (string) {
+	var res []string
+
+	if len(cc.User) > 0 { res = append(res,fmt.Sprintf("user=%s",cc.User)) }
+	if len(cc.Network) > 0 { res = append(res,fmt.Sprintf("network=%s",cc.Network)) }
+	if len(cc.Meta) > 0 { res = append(res,fmt.Sprintf("meta=%s",cc.Meta)) }
+	if len(cc.Vendor) > 0 { res = append(res,fmt.Sprintf("vendor=%s",cc.Vendor)) }
+
+	return strings.Join(res,",")
+}
+
+type CloudInitConfig struct {
+	Type string
+	User string
+	Password string
+	SSHKeys []string
+	IPConfigs []QemuIPConfig
+	Nameservers []string
+	SearchDomains []string
+	Custom CloudInitCustom
+	Upgrade bool
+}
+
+func (ci *CloudInitConfig) validate(errs *ValidationErrors) {
+	switch ci.Type {
+	case QEMU_CITYPE_NOCLOUD, QEMU_CITYPE_CONFIGDRIVE2, QEMU_CITYPE_OPENNEBULA, "":
+	default:
+		errs.add("citype", "wrong value. Posible values is: [%s|%s|%s|empty]", QEMU_CITYPE_NOCLOUD, QEMU_CITYPE_CONFIGDRIVE2, QEMU_CITYPE_OPENNEBULA)
+	}
+
+	if len(ci.User) > 0 && strings.ContainsAny(ci.User, " \t\n:") {
+		errs.add("ciuser", "wrong value: %s", ci.User)
+	}
+
+	for i, k := range ci.SSHKeys {
+		if len(strings.Fields(k)) < 2 {
+			errs.add("sshkeys", "wrong public key #%d. It shuld be <type> <key> [comment]", i)
+		}
+	}
+
+	indexes := make(map[int]bool)
+	for _, ic := range ci.IPConfigs {
+		key := "ipconfig" + strconv.Itoa(ic.Index)
+		if indexes[ic.Index] {
+			errs.add(key, "duplicate index")
+		}
+		indexes[ic.Index] = true
+		ic.validate(key, errs)
+	}
+
+	for _, ns := range ci.Nameservers {
+		if !isIPv4(ns) && !isIPv6(ns) {
+			errs.add("nameserver", "wrong IP address: %s", ns)
+		}
+	}
+
+	for _, sd := range ci.SearchDomains {
+		if !isDNSName(sd) {
+			errs.add("searchdomain", "wrong domain name: %s", sd)
+		}
+	}
+
+	for _, v := range []string{ci.Custom.User, ci.Custom.Network, ci.Custom.Meta, ci.Custom.Vendor} {
+		if len(v) > 0 && !hookscriptRegexp.MatchString(v) {
+			errs.add("cicustom", "wrong value: %s. It shuld be <storage>:snippets/<file>", v)
+		}
+	}
+}
+
+func (ci *CloudInitConfig) addUrlDataValues(data url.Values) {
+	if len(ci.Type) > 0 { data.Add("citype", ci.Type) }
+	if len(ci.User) > 0 { data.Add("ciuser", ci.User) }
+	if len(ci.Password) > 0 { data.Add("cipassword", ci.Password) }
+	if ci.Upgrade { data.Add("ciupgrade", "1") }
+	if len(ci.SSHKeys) > 0 { data.Add("sshkeys", encodeSSHKeys(ci.SSHKeys)) }
+	if len(ci.Nameservers) > 0 { data.Add("nameserver", strings.Join(ci.Nameservers, " ")) }
+	if len(ci.SearchDomains) > 0 { data.Add("searchdomain", strings.Join(ci.SearchDomains, " ")) }
+	if len(ci.Custom.String()) > 0 { data.Add("cicustom", ci.Custom.String()) }
+
+	for _, ic := range ci.IPConfigs {
+		if len(ic.String()) > 0 {
+			data.Add("ipconfig" + strconv.Itoa(ic.Index), ic.String())
+		}
+	}
+}
+
+// PVE expects sshkeys to be URL-encoded on top of the form encoding,
+// with spaces as %20 rather than '+'.
+func encodeSSHKeys(keys []string) string {
+	return strings.Replace(url.QueryEscape(strings.Join(keys, "\n")), "+", "%20", -1)
+}
+
+func parseSSHKeys(str string) []string {
+	decoded, err := url.PathUnescape(str)
+	if err != nil {
+		decoded = str
+	}
+
+	var keys []string
+	for _, k := range strings.Split(decoded, "\n") {
+		k = strings.TrimSpace(k)
+		if len(k) > 0 {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,480 @@
+package proxmox
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	websocketOpContinuation = 0x0
+	websocketOpText = 0x1
+	websocketOpBinary = 0x2
+	websocketOpClose = 0x8
+	websocketOpPing = 0x9
+	websocketOpPong = 0xA
+
+	// Upper bound for a single incoming frame; vncproxy/termproxy send far smaller ones.
+	websocketMaxFrameSize = 16 << 20
+)
+
+var qemuKeyNames = buildQemuKeyNames()
+
+var qemuHexKeyRegexp = regexp.MustCompile("^0x[0-9a-fA-F]{1,4}$")
+
+func buildQemuKeyNames() map[string]bool {
+	names := []string{
+		"shift", "shift_r", "alt", "alt_r", "altgr", "altgr_r", "ctrl", "ctrl_r", "menu", "esc",
+		"minus", "equal", "backspace", "tab", "bracket_left", "bracket_right", "ret", "semicolon",
+		"apostrophe", "grave_accent", "backslash", "comma", "dot", "slash", "asterisk", "spc",
+		"caps_lock", "num_lock", "scroll_lock", "kp_divide", "kp_multiply", "kp_subtract", "kp_add",
+		"kp_enter", "kp_decimal", "kp_equals", "kp_comma", "sysrq", "less", "home", "pgup", "pgdn",
+		"end", "left", "up", "down", "right", "insert", "delete", "stop", "again", "props", "undo",
+		"front", "copy", "open", "paste", "find", "cut", "lf", "help", "meta_l", "meta_r", "compose",
+		"pause", "print", "power", "sleep", "wake", "audionext", "audioprev", "audiostop",
+		"audioplay", "audiomute", "volumeup", "volumedown", "mediaselect", "mail", "calculator",
+		"computer", "ac_home", "ac_back", "ac_forward", "ac_refresh", "ac_bookmarks",
+	}
+
+	keyNames := make(map[string]bool)
+	for _, n := range names {
+		keyNames[n] = true
+	}
+	for c := 'a'; c <= 'z'; c++ {
+		keyNames[string(c)] = true
+	}
+	for i := 0; i <= 9; i++ {
+		keyNames[strconv.Itoa(i)] = true
+		keyNames["kp_" + strconv.Itoa(i)] = true
+	}
+	for i := 1; i <= 24; i++ {
+		keyNames["f" + strconv.Itoa(i)] = true
+	}
+
+	return keyNames
+}
+
+func ValidateQemuKey(key string) error {
+	if len(key) == 0 {
+		return errors.New("key could not be zero")
+	}
+
+	seen := make(map[string]bool)
+	for _, k := range strings.Split(key, "-") {
+		if len(k) == 0 {
+			return errors.New(fmt.Sprintf("wrong key combination: %s", key))
+		}
+		if !qemuKeyNames[k] && !qemuHexKeyRegexp.MatchString(k) {
+			return errors.New(fmt.Sprintf("unknown key name: %s in %s", k, key))
+		}
+		if seen[k] {
+			return errors.New(fmt.Sprintf("duplicate key: %s in %s", k, key))
+		}
+		seen[k] = true
+	}
+
+	return nil
+}
+
+type VNCProxyInfo struct {
+	Port PVEInt			`json:"port"`
+	Ticket string		`json:"ticket"`
+	Cert string			`json:"cert"`
+	User string			`json:"user"`
+	UPid TaskID			`json:"upid"`
+	Password string		`json:"password"`
+}
+
+type SpiceProxyInfo struct {
+	Type string				`json:"type"`
+	Title string			`json:"title"`
+	Host string				`json:"host"`
+	Port PVEInt				`json:"port"`
+	TLSPort PVEInt			`json:"tls-port"`
+	Password string			`json:"password"`
+	Proxy string			`json:"proxy"`
+	CA string				`json:"ca"`
+	HostSubject string		`json:"host-subject"`
+	SecureAttention string	`json:"secure-attention"`
+	ReleaseCursor string	`json:"release-cursor"`
+	ToggleFullscreen string	`json:"toggle-fullscreen"`
+	DeleteThisFile PVEInt	`json:"delete-this-file"`
+}
+
+func (q *Qemu) VNCProxy(websocket bool, generatePassword bool) (*VNCProxyInfo, error) {
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/vncproxy"
+
+	data := make(url.Values)
+	if websocket { data.Add("websocket", "1") }
+	if generatePassword { data.Add("generate-password", "1") }
+
+	var vncProxyInfo VNCProxyInfo
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("POST", target, data, &vncProxyInfo, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return &vncProxyInfo, nil
+}
+
+func (q *Qemu) VNCWebSocket(vncProxyInfo *VNCProxyInfo) (*WebSocketConn, error) {
+	if vncProxyInfo == nil || vncProxyInfo.Port == 0 || len(vncProxyInfo.Ticket) == 0 {
+		return nil, errors.New("vnc proxy info with port and ticket required")
+	}
+
+	path := API_TARGET + "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/vncwebsocket"
+
+	query := make(url.Values)
+	query.Add("port", strconv.Itoa(int(vncProxyInfo.Port)))
+	query.Add("vncticket", vncProxyInfo.Ticket)
+
+	return q.parent.(*Node).parent.(*Proxmox).dialWebSocket(path + "?" + query.Encode())
+}
+
+func (q *Qemu) SpiceProxy(proxy string) (*SpiceProxyInfo, error) {
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/spiceproxy"
+
+	data := make(url.Values)
+	if len(proxy) > 0 { data.Add("proxy", proxy) }
+
+	var spiceProxyInfo SpiceProxyInfo
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("POST", target, data, &spiceProxyInfo, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return &spiceProxyInfo, nil
+}
+
+func (spi *SpiceProxyInfo) VirtViewerFile() string {
+	var res []string
+
+	res = append(res, "[virt-viewer]")
+	add := func(key string, value string) {
+		if len(value) > 0 {
+			res = append(res, key + "=" + value)
+		}
+	}
+
+	add("type", spi.Type)
+	add("title", spi.Title)
+	add("host", spi.Host)
+	if spi.Port > 0 { add("port", strconv.Itoa(int(spi.Port))) }
+	if spi.TLSPort > 0 { add("tls-port", strconv.Itoa(int(spi.TLSPort))) }
+	add("password", spi.Password)
+	add("proxy", spi.Proxy)
+	add("ca", spi.CA)
+	add("host-subject", spi.HostSubject)
+	add("secure-attention", spi.SecureAttention)
+	add("release-cursor", spi.ReleaseCursor)
+	add("toggle-fullscreen", spi.ToggleFullscreen)
+	add("delete-this-file", strconv.Itoa(int(spi.DeleteThisFile)))
+
+	return strings.Join(res, "\n") + "\n"
+}
+
+func (q *Qemu) Monitor(command string) (string, error) {
+	if len(strings.TrimSpace(command)) == 0 {
+		return "", errors.New("command could not be zero")
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/monitor"
+
+	data := make(url.Values)
+	data.Add("command", command)
+
+	var result string
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("POST", target, data, &result, nil)
+	if err != nil {
+		return "", err
+	}
+	if httpCode != 200 {
+		return "", errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return result, nil
+}
+
+func (q *Qemu) SendKey(keys ...string) error {
+	if len(keys) == 0 {
+		return errors.New("keys could not be zero")
+	}
+
+	for _, k := range keys {
+		err := ValidateQemuKey(k)
+		if err != nil {
+			return err
+		}
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/sendkey"
+
+	for _, k := range keys {
+		data := make(url.Values)
+		data.Add("key", k)
+
+		var result interface{}
+
+		httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("PUT", target, data, &result, nil)
+		if err != nil {
+			return err
+		}
+		if httpCode != 200 {
+			return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+		}
+	}
+
+	return nil
+}
+
+type WebSocketConn struct {
+	conn net.Conn
+	reader *bufio.Reader
+	pending []byte
+	writeMutex sync.Mutex
+	closeMutex sync.Mutex
+	closed bool
+}
+
+func (px *Proxmox) dialWebSocket(path string) (*WebSocketConn, error) {
+	if time.Since(px.ticketTime) >= time.Duration(API_TOKEN_LIFETIME - API_TOKEN_UPDATEBEFORE) {
+		err := px.updateTicket()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: HTTP_TIMEOUT * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(px.host, px.port), &tls.Config{ InsecureSkipVerify: true })
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes := make([]byte, 16)
+	if _, err := rand.Read(keyBytes); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	request := "GET " + path + " HTTP/1.1\r\n" +
+		"Host: " + net.JoinHostPort(px.host, px.port) + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + key + "\r\n" +
+		"Sec-WebSocket-Version: 13\r\n" +
+		"Sec-WebSocket-Protocol: binary\r\n" +
+		"Cookie: PVEAuthCookie=" + url.QueryEscape(px.ticket) + "\r\n\r\n"
+
+	if _, err := conn.Write([]byte(request)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, &APIError{Code: response.StatusCode, Message: strings.TrimSpace(strings.TrimPrefix(response.Status, strconv.Itoa(response.StatusCode)))}
+	}
+
+	h := sha1.New()
+	h.Write([]byte(key + websocketGUID))
+	if response.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(h.Sum(nil)) {
+		conn.Close()
+		return nil, errors.New("websocket handshake failed: wrong Sec-WebSocket-Accept")
+	}
+
+	return &WebSocketConn{conn: conn, reader: reader}, nil
+}
+
+func (ws *WebSocketConn) Read(p []byte) (int, error) {
+	for len(ws.pending) == 0 {
+		if ws.isClosed() {
+			return 0, io.EOF
+		}
+
+		opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return 0, err
+		}
+
+		switch opcode {
+		case websocketOpContinuation, websocketOpText, websocketOpBinary:
+			ws.pending = payload
+		case websocketOpPing:
+			if err := ws.writeFrame(websocketOpPong, payload); err != nil {
+				return 0, err
+			}
+		case websocketOpClose:
+			if ws.markClosed() {
+				ws.writeFrame(websocketOpClose, nil)
+			}
+			return 0, io.EOF
+		}
+	}
+
+	n := copy(p, ws.pending)
+	ws.pending = ws.pending[n:]
+
+	return n, nil
+}
+
+func (ws *WebSocketConn) Write(p []byte) (int, error) {
+	if ws.isClosed() {
+		return 0, io.ErrClosedPipe
+	}
+
+	if err := ws.writeFrame(websocketOpBinary, p); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (ws *WebSocketConn) Close() error {
+	if ws.markClosed() {
+		ws.writeFrame(websocketOpClose, nil)
+	}
+
+	return ws.conn.Close()
+}
+
+func (ws *WebSocketConn) isClosed() bool {
+	ws.closeMutex.Lock()
+	defer ws.closeMutex.Unlock()
+
+	return ws.closed
+}
+
+// markClosed flags the connection as closed and reports whether this call did it.
+func (ws *WebSocketConn) markClosed() bool {
+	ws.closeMutex.Lock()
+	defer ws.closeMutex.Unlock()
+
+	if ws.closed {
+		return false
+	}
+	ws.closed = true
+
+	return true
+}
+
+func (ws *WebSocketConn) SetDeadline(t time.Time) error {
+	return ws.conn.SetDeadline(t)
+}
+
+func (ws *WebSocketConn) readFrame() (byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(ws.reader, header); err != nil {
+		return 0, nil, err
+	}
+
+	opcode := header[0] & 0x0F
+	masked := header[1] & 0x80 != 0
+	length := uint64(header[1] & 0x7F)
+
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(ws.reader, ext); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(ws.reader, ext); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+
+	if length > websocketMaxFrameSize {
+		return 0, nil, errors.New(fmt.Sprintf("websocket frame too large: %d bytes (max %d)", length, websocketMaxFrameSize))
+	}
+
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(ws.reader, mask); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.reader, payload); err != nil {
+		return 0, nil, err
+	}
+
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i % 4]
+		}
+	}
+
+	return opcode, payload, nil
+}
+
+func (ws *WebSocketConn) writeFrame(opcode byte, payload []byte) error {
+	ws.writeMutex.Lock()
+	defer ws.writeMutex.Unlock()
+
+	frame := []byte{0x80 | opcode}
+
+	length := len(payload)
+	switch {
+	case length < 126:
+		frame = append(frame, 0x80 | byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 0x80 | 126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, 0x80 | 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+
+	for i, b := range payload {
+		frame = append(frame, b ^ mask[i % 4])
+	}
+
+	_, err := ws.conn.Write(frame)
+
+	return err
+}
@@ -0,0 +1,184 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var qemuDiskKeyRegexp = regexp.MustCompile("^((scsi|virtio|sata|ide|unused)\\d+|efidisk0|tpmstate0)$")
+var qemuUnusedKeyRegexp = regexp.MustCompile("^unused\\d+$")
+var qemuDiskSizeRegexp = regexp.MustCompile("^\\+?\\d+(\\.\\d+)?[KMGT]?$")
+
+func (q *Qemu) GetConfigDigest() (string, error) {
+	qemuConfig, err := q.GetConfig()
+	if err != nil {
+		return "", err
+	}
+
+	return qemuConfig.Digest, nil
+}
+
+func (q *Qemu) ResizeDisk(disk string, size string, digest string) (*Task, error) {
+	if !qemuDiskKeyRegexp.MatchString(disk) || qemuUnusedKeyRegexp.MatchString(disk) {
+		return nil, errors.New(fmt.Sprintf("wrong disk: %s", disk))
+	}
+
+	if !qemuDiskSizeRegexp.MatchString(size) {
+		return nil, errors.New(fmt.Sprintf("wrong size: %s. It shuld be [+]<number>[K|M|G|T]", size))
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/resize"
+
+	data := make(url.Values)
+	data.Add("disk", disk)
+	data.Add("size", size)
+	if len(digest) > 0 { data.Add("digest", digest) }
+
+	return q.taskCall("PUT", target, data)
+}
+
+func (q *Qemu) MoveDisk(disk string, storage string, format string, deleteSource bool, bwlimit int, digest string) (*Task, error) {
+	if !qemuDiskKeyRegexp.MatchString(disk) {
+		return nil, errors.New(fmt.Sprintf("wrong disk: %s", disk))
+	}
+
+	if len(storage) == 0 {
+		return nil, errors.New("storage could not be zero")
+	}
+
+	switch format {
+	case "raw", "qcow2", "vmdk", "":
+	default:
+		return nil, errors.New("format has wrong value. Posible values is: [raw|qcow2|vmdk|empty]")
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/move_disk"
+
+	data := make(url.Values)
+	data.Add("disk", disk)
+	data.Add("storage", storage)
+	if len(digest) > 0 { data.Add("digest", digest) }
+	if len(format) > 0 { data.Add("format", format) }
+	if deleteSource { data.Add("delete", "1") }
+	if bwlimit > 0 { data.Add("bwlimit", strconv.Itoa(bwlimit)) }
+
+	return q.taskCall("POST", target, data)
+}
+
+func (q *Qemu) ReassignDisk(disk string, targetVmId int64, targetDisk string, digest string, targetDigest string) (*Task, error) {
+	if !qemuDiskKeyRegexp.MatchString(disk) || disk == "efidisk0" || disk == "tpmstate0" {
+		return nil, errors.New(fmt.Sprintf("wrong disk: %s", disk))
+	}
+
+	if !qemuDiskKeyRegexp.MatchString(targetDisk) || targetDisk == "efidisk0" || targetDisk == "tpmstate0" {
+		return nil, errors.New(fmt.Sprintf("wrong target disk: %s", targetDisk))
+	}
+
+	if targetVmId == q.VmId {
+		return nil, errors.New("target VMID could not be the same as source")
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/move_disk"
+
+	data := make(url.Values)
+	data.Add("disk", disk)
+	data.Add("target-vmid", strconv.Itoa(int(targetVmId)))
+	data.Add("target-disk", targetDisk)
+	if len(digest) > 0 { data.Add("digest", digest) }
+	if len(targetDigest) > 0 { data.Add("target-digest", targetDigest) }
+
+	return q.taskCall("POST", target, data)
+}
+
+func (q *Qemu) DetachDisk(disk string, digest string) error {
+	if !qemuDiskKeyRegexp.MatchString(disk) || qemuUnusedKeyRegexp.MatchString(disk) {
+		return errors.New(fmt.Sprintf("wrong disk: %s", disk))
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/config"
+
+	data := make(url.Values)
+	data.Add("delete", disk)
+	if len(digest) > 0 { data.Add("digest", digest) }
+
+	var result interface{}
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("PUT", target, data, &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return nil
+}
+
+func (q *Qemu) UnlinkDisks(disks []string, force bool) error {
+	if len(disks) == 0 {
+		return errors.New("disks could not be zero")
+	}
+
+	for _, d := range disks {
+		if !qemuDiskKeyRegexp.MatchString(d) {
+			return errors.New(fmt.Sprintf("wrong disk: %s", d))
+		}
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/unlink"
+
+	data := make(url.Values)
+	data.Add("idlist", strings.Join(disks, ","))
+	if force { data.Add("force", "1") }
+
+	var result interface{}
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("PUT", target, data, &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return nil
+}
+
+// ImportDisk attaches a copy of source (a volume ID or an absolute path on the
+// node) as a new disk allocated on storage. disk.Volume must be empty, PVE picks
+// the new volume name.
+func (q *Qemu) ImportDisk(disk QemuDisk, storage string, source string, digest string) (*Task, error) {
+	if len(source) == 0 {
+		return nil, errors.New("source could not be zero")
+	}
+
+	if !storageIdRegexp.MatchString(storage) {
+		return nil, errors.New(fmt.Sprintf("wrong storage: %s", storage))
+	}
+
+	if len(disk.Volume) > 0 {
+		return nil, errors.New("disk volume shuld be empty, it is allocated on storage")
+	}
+
+	// <storage>:0 asks PVE to allocate a new volume for the imported data.
+	disk.Volume = storage + ":0"
+	disk.Size = ""
+
+	var errs ValidationErrors
+	disk.validate(&errs)
+	if err := errs.orNil(); err != nil {
+		return nil, err
+	}
+
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/config"
+
+	data := make(url.Values)
+	data.Add(disk.Key(), disk.String() + ",import-from=" + source)
+	if len(digest) > 0 { data.Add("digest", digest) }
+
+	return q.taskCall("POST", target, data)
+}
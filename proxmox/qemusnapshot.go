@@ -0,0 +1,102 @@
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const (
+	QEMU_SNAPSHOT_CURRENT = SNAPSHOT_CURRENT
+)
+
+type QemuSnapshot = GuestSnapshot
+
+func (q *Qemu) snapshotTarget(snapname string) string {
+	target := "nodes/" + q.parent.(*Node).Node + "/qemu/" + strconv.Itoa(int(q.VmId)) + "/snapshot"
+	if len(snapname) > 0 {
+		target += "/" + url.PathEscape(snapname)
+	}
+	return target
+}
+
+func (q *Qemu) Snapshot(snapname string, description string, vmstate bool) (*Task, error) {
+	if !snapnameRegexp.MatchString(snapname) || snapname == QEMU_SNAPSHOT_CURRENT {
+		return nil, errors.New(fmt.Sprintf("wrong snapname: %s", snapname))
+	}
+
+	data := make(url.Values)
+	data.Add("snapname", snapname)
+	if len(description) > 0 { data.Add("description", description) }
+	if vmstate { data.Add("vmstate", "1") }
+
+	return q.taskCall("POST", q.snapshotTarget(""), data)
+}
+
+func (q *Qemu) GetSnapshots() ([]QemuSnapshot, error) {
+	var snapshots []QemuSnapshot
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("GET", q.snapshotTarget(""), nil, &snapshots, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return snapshots, nil
+}
+
+func (q *Qemu) GetSnapshotTree() ([]*QemuSnapshot, error) {
+	snapshots, err := q.GetSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSnapshotTree(snapshots), nil
+}
+
+func (q *Qemu) CreateSnapshot(snapname string, description string) (*Task, error) {
+	return q.Snapshot(snapname, description, false)
+}
+
+func (q *Qemu) GetSnapshotConfig(snapname string) (*QemuConfig, error) {
+	if len(snapname) == 0 {
+		return nil, errors.New("snapname could not be zero")
+	}
+
+	var qemuConfigReceiver QemuConfigReceiver
+
+	httpCode, err := q.parent.(*Node).parent.(*Proxmox).APICall2("GET", q.snapshotTarget(snapname) + "/config", nil, &qemuConfigReceiver, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return qemuConfigReceiver.Parse()
+}
+
+func (q *Qemu) RollbackSnapshot(snapname string, start bool) (*Task, error) {
+	if len(snapname) == 0 || snapname == QEMU_SNAPSHOT_CURRENT {
+		return nil, errors.New(fmt.Sprintf("wrong snapname: %s", snapname))
+	}
+
+	data := make(url.Values)
+	if start { data.Add("start", "1") }
+
+	return q.taskCall("POST", q.snapshotTarget(snapname) + "/rollback", data)
+}
+
+func (q *Qemu) DeleteSnapshot(snapname string, force bool) (*Task, error) {
+	if len(snapname) == 0 || snapname == QEMU_SNAPSHOT_CURRENT {
+		return nil, errors.New(fmt.Sprintf("wrong snapname: %s", snapname))
+	}
+
+	data := make(url.Values)
+	if force { data.Add("force", "1") }
+
+	return q.taskCall("DELETE", q.snapshotTarget(snapname), data)
+}
@@ -3,6 +3,8 @@ package proxmox
 import (
 	"github.com/kataras/iris/core/errors"
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -26,6 +28,11 @@ type TaskStatus struct {
 	BaseTask
 }
 
+type TaskLogLine struct {
+	N int		`json:"n"`
+	T string	`json:"t"`
+}
+
 type Task struct {
 	EndTime int		`json:"endtime"`
 	BaseTask
@@ -70,4 +77,28 @@ func (t *Task) WaitForStatus(status string, timeout int) (bool,*TaskStatus,error
 	}
 
 	return false, taskStatus,errors.New("timeout reached, status not get")
-}
\ No newline at end of file
+}
+
+func (t *Task) GetLog(start int, limit int) ([]TaskLogLine, error) {
+	if len(string(t.UPid)) == 0 { return nil, errors.New("Can't get log of nil")}
+
+	upparts := strings.Split(string(t.UPid),":")
+
+	target := "nodes/" + upparts[1] + "/tasks/" + string(t.UPid) + "/log"
+
+	data := make(url.Values)
+	if start > 0 { data.Add("start", strconv.Itoa(start)) }
+	if limit > 0 { data.Add("limit", strconv.Itoa(limit)) }
+
+	var taskLog []TaskLogLine
+
+	httpCode, err := t.GetProxmox().APICall2("GET", target, data, &taskLog, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d",httpCode))
+	}
+
+	return taskLog, nil
+}
@@ -0,0 +1,76 @@
+package proxmox
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+type PVEBool bool
+
+type PVEInt int64
+
+type PVEFloat float64
+
+func (b *PVEBool) UnmarshalJSON(data []byte) error {
+	str := string(bytes.Trim(data, "\""))
+
+	switch str {
+	case "1", "true", "yes", "on":
+		*b = true
+	case "0", "false", "no", "off", "", "null":
+		*b = false
+	default:
+		return errors.New("PVEBool has wrong value: " + str)
+	}
+
+	return nil
+}
+
+func (i *PVEInt) UnmarshalJSON(data []byte) error {
+	str := string(bytes.Trim(data, "\""))
+
+	if str == "" || str == "null" {
+		*i = 0
+		return nil
+	}
+
+	v, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		f, ferr := strconv.ParseFloat(str, 64)
+		if ferr != nil {
+			return errors.New("PVEInt has wrong value: " + str)
+		}
+		v = int64(f)
+	}
+
+	*i = PVEInt(v)
+
+	return nil
+}
+
+func (f *PVEFloat) UnmarshalJSON(data []byte) error {
+	str := string(bytes.Trim(data, "\""))
+
+	if str == "" || str == "null" {
+		*f = 0
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return errors.New("PVEFloat has wrong value: " + str)
+	}
+
+	*f = PVEFloat(v)
+
+	return nil
+}
+
+func StringPtr(v string) *string { return &v }
+
+func Int64Ptr(v int64) *int64 { return &v }
+
+func Float64Ptr(v float64) *float64 { return &v }
+
+func BoolPtr(v bool) *bool { return &v }
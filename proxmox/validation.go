@@ -0,0 +1,91 @@
+package proxmox
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+type ValidationError struct {
+	Field string
+	Message string
+}
+
+type ValidationErrors []ValidationError
+
+var (
+	dnsLabelRegexp = regexp.MustCompile("^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$")
+	macAddrRegexp = regexp.MustCompile("^[a-fA-F0-9][02468aceACE](:[a-fA-F0-9]{2}){5}$")
+	ifaceNameRegexp = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9_.-]{0,14}$")
+	poolNameRegexp = regexp.MustCompile("^[A-Za-z0-9_\\-]+$")
+	tagRegexp = regexp.MustCompile("^(?i)[a-z0-9_][a-z0-9_\\-+.]*$")
+	hookscriptRegexp = regexp.MustCompile("^[^\\s:]+:snippets/\\S+$")
+	fsTypeRegexp = regexp.MustCompile("^[a-z0-9_.]+$")
+	octalModeRegexp = regexp.MustCompile("^0?[0-7]{3}$")
+)
+
+func (ve ValidationError) Error() string {
+	return ve.Field + ": " + ve.Message
+}
+
+func (ve ValidationErrors) Error() string {
+	var res []string
+	for _, e := range ve {
+		res = append(res, e.Error())
+	}
+	return strings.Join(res, "; ")
+}
+
+func (ve ValidationErrors) Has(field string) bool {
+	for _, e := range ve {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (ve *ValidationErrors) add(field string, format string, args ...interface{}) {
+	*ve = append(*ve, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (ve ValidationErrors) orNil() error {
+	if len(ve) == 0 {
+		return nil
+	}
+	return ve
+}
+
+func isDNSName(str string) bool {
+	if len(str) == 0 || len(str) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(str, ".") {
+		if !dnsLabelRegexp.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIPv4(str string) bool {
+	ip := net.ParseIP(str)
+	return ip != nil && ip.To4() != nil && !strings.Contains(str, ":")
+}
+
+func isIPv6(str string) bool {
+	ip := net.ParseIP(str)
+	return ip != nil && strings.Contains(str, ":")
+}
+
+func parseCIDR(str string, v6 bool) (net.IP, bool) {
+	ip, _, err := net.ParseCIDR(str)
+	if err != nil {
+		return nil, false
+	}
+	if v6 != strings.Contains(str, ":") {
+		return nil, false
+	}
+	return ip, true
+}
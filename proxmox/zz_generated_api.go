@@ -0,0 +1,417 @@
+// Code generated by apigen from apidoc.json. DO NOT EDIT.
+
+package proxmox
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+type CreatePoolsParams struct {
+	Comment *string `json:"comment,omitempty"`
+	// Format: pve-poolid.
+	Poolid string `json:"poolid"`
+}
+
+func (p *CreatePoolsParams) Validate() error {
+	var errs ValidationErrors
+	if p.Comment != nil {
+		if len(*p.Comment) > 4096 {
+			errs.add("comment", "too long. It shuld be 0-4096 characters")
+		}
+	}
+	if len(p.Poolid) == 0 {
+		errs.add("poolid", "could not be zero")
+	}
+	if len(p.Poolid) > 0 && !poolNameRegexp.MatchString(p.Poolid) {
+		errs.add("poolid", "wrong value: %s", p.Poolid)
+	}
+	return errs.orNil()
+}
+
+func (p *CreatePoolsParams) values() url.Values {
+	data := make(url.Values)
+	if p.Comment != nil {
+		data.Add("comment", *p.Comment)
+	}
+	data.Add("poolid", p.Poolid)
+	return data
+}
+
+// CreatePools calls POST /pools. Create new pool.
+func (api *API) CreatePools(params *CreatePoolsParams) error {
+	if params == nil {
+		params = &CreatePoolsParams{}
+	}
+
+	err := params.Validate()
+	if err != nil {
+		return err
+	}
+
+	target := "pools"
+
+	var result interface{}
+
+	httpCode, err := api.px.APICall2("POST", target, params.values(), &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d", httpCode))
+	}
+
+	return nil
+}
+
+type DeletePoolsByPoolidParams struct {
+	// Format: pve-poolid.
+	Poolid string `json:"poolid"`
+}
+
+func (p *DeletePoolsByPoolidParams) Validate() error {
+	var errs ValidationErrors
+	if len(p.Poolid) == 0 {
+		errs.add("poolid", "could not be zero")
+	}
+	if len(p.Poolid) > 0 && !poolNameRegexp.MatchString(p.Poolid) {
+		errs.add("poolid", "wrong value: %s", p.Poolid)
+	}
+	return errs.orNil()
+}
+
+func (p *DeletePoolsByPoolidParams) values() url.Values {
+	data := make(url.Values)
+	return data
+}
+
+// DeletePoolsByPoolid calls DELETE /pools/{poolid}. Delete pool.
+func (api *API) DeletePoolsByPoolid(params *DeletePoolsByPoolidParams) error {
+	if params == nil {
+		params = &DeletePoolsByPoolidParams{}
+	}
+
+	err := params.Validate()
+	if err != nil {
+		return err
+	}
+
+	target := "pools/" + url.PathEscape(params.Poolid)
+
+	var result interface{}
+
+	httpCode, err := api.px.APICall2("DELETE", target, params.values(), &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d", httpCode))
+	}
+
+	return nil
+}
+
+type GetAccessUsersParams struct {
+	// Optional filter for enable property.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Include group and token information.
+	Full *bool `json:"full,omitempty"`
+}
+
+func (p *GetAccessUsersParams) Validate() error {
+	var errs ValidationErrors
+	return errs.orNil()
+}
+
+func (p *GetAccessUsersParams) values() url.Values {
+	data := make(url.Values)
+	if p.Enabled != nil {
+		if *p.Enabled {
+			data.Add("enabled", "1")
+		} else {
+			data.Add("enabled", "0")
+		}
+	}
+	if p.Full != nil {
+		if *p.Full {
+			data.Add("full", "1")
+		} else {
+			data.Add("full", "0")
+		}
+	}
+	return data
+}
+
+type GetAccessUsersResponse struct {
+	Comment   string  `json:"comment,omitempty"`
+	Email     string  `json:"email,omitempty"`
+	Enable    PVEBool `json:"enable,omitempty"`
+	Expire    PVEInt  `json:"expire,omitempty"`
+	Firstname string  `json:"firstname,omitempty"`
+	Groups    string  `json:"groups,omitempty"`
+	Lastname  string  `json:"lastname,omitempty"`
+	RealmType string  `json:"realm-type,omitempty"`
+	Userid    string  `json:"userid"`
+}
+
+// GetAccessUsers calls GET /access/users. User index.
+func (api *API) GetAccessUsers(params *GetAccessUsersParams) ([]GetAccessUsersResponse, error) {
+	if params == nil {
+		params = &GetAccessUsersParams{}
+	}
+
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	target := "access/users"
+
+	var result []GetAccessUsersResponse
+
+	httpCode, err := api.px.APICall2("GET", target, params.values(), &result, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d", httpCode))
+	}
+
+	return result, nil
+}
+
+type GetClusterNextidParams struct {
+	// The (unique) ID of the VM.
+	Vmid *int64 `json:"vmid,omitempty"`
+}
+
+func (p *GetClusterNextidParams) Validate() error {
+	var errs ValidationErrors
+	if p.Vmid != nil {
+		if *p.Vmid < 100 {
+			errs.add("vmid", "wrong value. It shuld be 100-N")
+		}
+		if *p.Vmid > 999999999 {
+			errs.add("vmid", "wrong value. It shuld be less or equal 999999999")
+		}
+	}
+	return errs.orNil()
+}
+
+func (p *GetClusterNextidParams) values() url.Values {
+	data := make(url.Values)
+	if p.Vmid != nil {
+		data.Add("vmid", strconv.FormatInt(*p.Vmid, 10))
+	}
+	return data
+}
+
+// GetClusterNextid calls GET /cluster/nextid. Get next free VMID. Pass a VMID to assert that its free (at time of check).
+func (api *API) GetClusterNextid(params *GetClusterNextidParams) (int64, error) {
+	if params == nil {
+		params = &GetClusterNextidParams{}
+	}
+
+	err := params.Validate()
+	if err != nil {
+		return 0, err
+	}
+
+	target := "cluster/nextid"
+
+	var result PVEInt
+
+	httpCode, err := api.px.APICall2("GET", target, params.values(), &result, nil)
+	if err != nil {
+		return 0, err
+	}
+	if httpCode != 200 {
+		return 0, errors.New(fmt.Sprintf("HTTP Request return error: %d", httpCode))
+	}
+
+	return int64(result), nil
+}
+
+type GetPoolsResponse struct {
+	Comment string `json:"comment,omitempty"`
+	Poolid  string `json:"poolid"`
+}
+
+// GetPools calls GET /pools. Pool index.
+func (api *API) GetPools() ([]GetPoolsResponse, error) {
+	target := "pools"
+
+	var result []GetPoolsResponse
+
+	httpCode, err := api.px.APICall2("GET", target, nil, &result, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d", httpCode))
+	}
+
+	return result, nil
+}
+
+type GetPoolsByPoolidParams struct {
+	// Format: pve-poolid.
+	Poolid string  `json:"poolid"`
+	Type   *string `json:"type,omitempty"`
+}
+
+const (
+	GetPoolsByPoolidParamsTypeQemu    = "qemu"
+	GetPoolsByPoolidParamsTypeLxc     = "lxc"
+	GetPoolsByPoolidParamsTypeStorage = "storage"
+)
+
+func (p *GetPoolsByPoolidParams) Validate() error {
+	var errs ValidationErrors
+	if len(p.Poolid) == 0 {
+		errs.add("poolid", "could not be zero")
+	}
+	if len(p.Poolid) > 0 && !poolNameRegexp.MatchString(p.Poolid) {
+		errs.add("poolid", "wrong value: %s", p.Poolid)
+	}
+	if p.Type != nil {
+		switch *p.Type {
+		case "qemu", "lxc", "storage":
+		default:
+			errs.add("type", "wrong value. Posible values is: [qemu|lxc|storage]")
+		}
+	}
+	return errs.orNil()
+}
+
+func (p *GetPoolsByPoolidParams) values() url.Values {
+	data := make(url.Values)
+	if p.Type != nil {
+		data.Add("type", *p.Type)
+	}
+	return data
+}
+
+type GetPoolsByPoolidResponse struct {
+	Comment string        `json:"comment,omitempty"`
+	Members []interface{} `json:"members"`
+}
+
+// GetPoolsByPoolid calls GET /pools/{poolid}. Get pool configuration.
+func (api *API) GetPoolsByPoolid(params *GetPoolsByPoolidParams) (*GetPoolsByPoolidResponse, error) {
+	if params == nil {
+		params = &GetPoolsByPoolidParams{}
+	}
+
+	err := params.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	target := "pools/" + url.PathEscape(params.Poolid)
+
+	var result GetPoolsByPoolidResponse
+
+	httpCode, err := api.px.APICall2("GET", target, params.values(), &result, nil)
+	if err != nil {
+		return nil, err
+	}
+	if httpCode != 200 {
+		return nil, errors.New(fmt.Sprintf("HTTP Request return error: %d", httpCode))
+	}
+
+	return &result, nil
+}
+
+type UpdatePoolsByPoolidParams struct {
+	Comment *string `json:"comment,omitempty"`
+	// Remove vms/storage (instead of adding it).
+	Delete *bool `json:"delete,omitempty"`
+	// Format: pve-poolid.
+	Poolid string `json:"poolid"`
+	// List of storage IDs.
+	// Format: pve-storage-id-list.
+	Storage *string `json:"storage,omitempty"`
+	// List of virtual machines.
+	// Format: pve-vmid-list.
+	Vms *string `json:"vms,omitempty"`
+}
+
+func (p *UpdatePoolsByPoolidParams) Validate() error {
+	var errs ValidationErrors
+	if p.Comment != nil {
+		if len(*p.Comment) > 4096 {
+			errs.add("comment", "too long. It shuld be 0-4096 characters")
+		}
+	}
+	if len(p.Poolid) == 0 {
+		errs.add("poolid", "could not be zero")
+	}
+	if len(p.Poolid) > 0 && !poolNameRegexp.MatchString(p.Poolid) {
+		errs.add("poolid", "wrong value: %s", p.Poolid)
+	}
+	if p.Storage != nil {
+		for _, v := range splitList(*p.Storage) {
+			if !storageIdRegexp.MatchString(v) {
+				errs.add("storage", "wrong value: %s", v)
+			}
+		}
+	}
+	if p.Vms != nil {
+		for _, v := range splitList(*p.Vms) {
+			if !isVmIdString(v) {
+				errs.add("vms", "wrong value: %s", v)
+			}
+		}
+	}
+	return errs.orNil()
+}
+
+func (p *UpdatePoolsByPoolidParams) values() url.Values {
+	data := make(url.Values)
+	if p.Comment != nil {
+		data.Add("comment", *p.Comment)
+	}
+	if p.Delete != nil {
+		if *p.Delete {
+			data.Add("delete", "1")
+		} else {
+			data.Add("delete", "0")
+		}
+	}
+	if p.Storage != nil {
+		data.Add("storage", *p.Storage)
+	}
+	if p.Vms != nil {
+		data.Add("vms", *p.Vms)
+	}
+	return data
+}
+
+// UpdatePoolsByPoolid calls PUT /pools/{poolid}. Update pool data.
+func (api *API) UpdatePoolsByPoolid(params *UpdatePoolsByPoolidParams) error {
+	if params == nil {
+		params = &UpdatePoolsByPoolidParams{}
+	}
+
+	err := params.Validate()
+	if err != nil {
+		return err
+	}
+
+	target := "pools/" + url.PathEscape(params.Poolid)
+
+	var result interface{}
+
+	httpCode, err := api.px.APICall2("PUT", target, params.values(), &result, nil)
+	if err != nil {
+		return err
+	}
+	if httpCode != 200 {
+		return errors.New(fmt.Sprintf("HTTP Request return error: %d", httpCode))
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package proxmox_test
+
+import (
+	"testing"
+	. "github.com/mrgloba/proxmox-api2/proxmox"
+)
+
+func TestAPI_GetClusterNextid(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  *GetClusterNextidParams
+		wantErr bool
+	}{
+		{
+			name:    "API.GetClusterNextid() test",
+			params:  nil,
+			wantErr: false,
+		},
+		{
+			name:    "API.GetClusterNextid() wrong vmid test",
+			params:  &GetClusterNextidParams{Vmid: Int64Ptr(1)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.API().GetClusterNextid(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("API.GetClusterNextid() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			if !tt.wantErr && got < 100 {
+				t.Errorf("API.GetClusterNextid() = %v", got)
+			}
+		})
+	}
+}
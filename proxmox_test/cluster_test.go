@@ -0,0 +1,121 @@
+package proxmox_test
+
+import (
+	"errors"
+	"testing"
+	. "github.com/mrgloba/proxmox-api2/proxmox"
+)
+
+func TestProxmox_NextID(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "Proxmox.NextID() test", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := server.NextID()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Proxmox.NextID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			free, err := server.IsFreeID(got)
+			if err != nil || !free {
+				t.Errorf("Proxmox.IsFreeID(%d) = %v, %v", got, free, err)
+			}
+		})
+	}
+}
+
+func TestVmIdAllocator_Reserve(t *testing.T) {
+	type args struct {
+		min int64
+		max int64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "VmIdAllocator.Reserve() test",
+			args:    args{min: 5000, max: 5099},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocator, err := server.NewVmIdAllocator(tt.args.min, tt.args.max)
+			if err != nil {
+				t.Errorf("Proxmox.NewVmIdAllocator() error = %v", err)
+				return
+			}
+
+			first, err := allocator.Reserve()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VmIdAllocator.Reserve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			second, err := allocator.Reserve()
+			if err != nil {
+				t.Errorf("VmIdAllocator.Reserve() error = %v", err)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("%v %v\n", first, second)
+			}
+
+			if first == second || first < tt.args.min || second > tt.args.max {
+				t.Errorf("VmIdAllocator.Reserve() = %v, %v", first, second)
+			}
+
+			if free, _ := allocator.IsFree(first); free {
+				t.Errorf("VmIdAllocator.IsFree(%d) = true for reserved id", first)
+			}
+		})
+	}
+}
+
+func TestIsAlreadyExists(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "IsAlreadyExists() message",
+			err:  &APIError{Code: 500, Message: "unable to create CT 101 - CT 101 already exists on node 'pve'"},
+			want: true,
+		},
+		{
+			name: "IsAlreadyExists() parameter error",
+			err:  &APIError{Code: 400, Message: "Parameter verification failed.", Errors: map[string]string{"vmid": "VM 101 already exists\n"}},
+			want: true,
+		},
+		{
+			name: "IsAlreadyExists() other parameter error",
+			err:  &APIError{Code: 400, Message: "Parameter verification failed.", Errors: map[string]string{"vmid": "value must have a minimum value of 100\n"}},
+			want: false,
+		},
+		{
+			name: "IsAlreadyExists() not an API error",
+			err:  errors.New("already exists"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAlreadyExists(tt.err); got != tt.want {
+				t.Errorf("IsAlreadyExists() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
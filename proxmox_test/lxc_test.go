@@ -522,3 +522,275 @@ func TestLxc_Shutdown(t *testing.T) {
 	}
 }
 
+
+func TestLxc_CheckFeature(t *testing.T) {
+	type args struct {
+		feature  string
+		snapshot string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "Lxc.CheckFeature() snapshot test",
+			args:    args{feature: LXC_FEATURE_SNAPSHOT},
+			wantErr: false,
+		},
+		{
+			name:    "Lxc.CheckFeature() copy test",
+			args:    args{feature: LXC_FEATURE_COPY},
+			wantErr: false,
+		},
+		{
+			name:    "Lxc.CheckFeature() wrong feature test",
+			args:    args{feature: "migrate"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+
+			lxc, err := nodes[0].GetLxc(TEST_PROXMOX_VMID)
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			got, err := lxc.CheckFeature(tt.args.feature, tt.args.snapshot)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Lxc.CheckFeature() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("Lxc: %v\n", got)
+			}
+		})
+	}
+}
+
+func TestLxcFeatures_SetFromString(t *testing.T) {
+	type args struct {
+		str string
+	}
+	tests := []struct {
+		name string
+		args args
+		want LxcFeatures
+	}{
+		{
+			name: "LxcFeatures.SetFromString() test",
+			args: args{str: "nesting=1,keyctl=1,mount=nfs;cifs"},
+			want: LxcFeatures{Nesting: true, KeyCtl: true, Mount: []string{"nfs", "cifs"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf := LxcFeatures{}
+			lf.SetFromString(tt.args.str)
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", lf)
+			}
+			if !reflect.DeepEqual(lf, tt.want) {
+				t.Errorf("LxcFeatures.SetFromString() = %v, want %v", lf, tt.want)
+			}
+		})
+	}
+}
+
+func TestLxcFeatures_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		features LxcFeatures
+		want     string
+	}{
+		{
+			name:     "LxcFeatures.String() test1",
+			features: LxcFeatures{Nesting: true, KeyCtl: true},
+			want:     "nesting=1,keyctl=1",
+		},
+		{
+			name:     "LxcFeatures.String() test2",
+			features: LxcFeatures{Fuse: true, MkNod: true, Mount: []string{"nfs", "cifs"}},
+			want:     "fuse=1,mknod=1,mount=nfs;cifs",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.features.String()
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			if got != tt.want {
+				t.Errorf("LxcFeatures.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeviceConfig_SetFromString(t *testing.T) {
+	type args struct {
+		idx int
+		str string
+	}
+	tests := []struct {
+		name string
+		args args
+		want DeviceConfig
+	}{
+		{
+			name: "DeviceConfig.SetFromString() test1",
+			args: args{idx: 0, str: "/dev/ttyUSB0,mode=0660,gid=20"},
+			want: DeviceConfig{Index: 0, Path: "/dev/ttyUSB0", Mode: "0660", GID: 20},
+		},
+		{
+			name: "DeviceConfig.SetFromString() test2",
+			args: args{idx: 1, str: "path=/dev/net/tun,deny-write=1"},
+			want: DeviceConfig{Index: 1, Path: "/dev/net/tun", DenyWrite: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := DeviceConfig{}
+			dc.SetFromString(tt.args.idx, tt.args.str)
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", dc)
+			}
+
+			if !reflect.DeepEqual(dc, tt.want) {
+				t.Errorf("DeviceConfig.SetFromString() = %v, want %v", dc, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeviceConfig_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		device DeviceConfig
+		want   string
+	}{
+		{
+			name:   "DeviceConfig.String() test",
+			device: DeviceConfig{Index: 0, Path: "/dev/ttyUSB0", Mode: "0660", UID: 100, GID: 20},
+			want:   "/dev/ttyUSB0,mode=0660,uid=100,gid=20",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.device.String()
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			if got != tt.want {
+				t.Errorf("DeviceConfig.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLxcConfig_Validate(t *testing.T) {
+	valid := BaseLxcConfig{
+		VmId:       TEST_PROXMOX_VMID,
+		Hostname:   "test1",
+		Password:   "111111",
+		OSTemplate: TEST_PROXMOX_TEMPLATE,
+		RootFS:     TEST_PROXMOX_STORAGE + ":10",
+		Cores:      1,
+		Memory:     512,
+	}
+	tests := []struct {
+		name       string
+		clp        LxcConfig
+		wantFields []string
+	}{
+		{
+			name: "LxcConfig.Validate() valid config",
+			clp: LxcConfig{
+				BaseLxcConfig: valid,
+				Networks:      []NetworkConfig{{Name: "eth0", Bridge: "vmbr0", IPAddress: "10.10.10.2/24", Gateway: "10.10.10.1", Tag: 99}},
+				MountPoints:   []MountPoint{{Index: 0, Volume: "local-lvm:8", Path: "/data"}},
+			},
+		},
+		{
+			name: "LxcConfig.Validate() base fields",
+			clp: LxcConfig{
+				BaseLxcConfig: BaseLxcConfig{
+					Hostname: "-bad_host",
+					CpuUnits: 600000,
+					Lock:     "unknown",
+					OSType:   "windows",
+				},
+			},
+			wantFields: []string{"cores", "cpuunits", "hostname", "lock", "memory", "ostemplate", "ostype", "password", "rootfs"},
+		},
+		{
+			name: "LxcConfig.Validate() networks and mount points",
+			clp: LxcConfig{
+				BaseLxcConfig: valid,
+				Networks: []NetworkConfig{
+					{Index: 0, Name: "eth0", IPAddress: "dhcp", Gateway: "10.10.10.1", HWAddr: "01:00:5E:00:00:01", Tag: 5000},
+					{Index: 0, Name: "eth0", IPAddress: "10.10.10.2", GatewayV6: "10.10.10.1"},
+				},
+				MountPoints: []MountPoint{
+					{Index: 1, Volume: "local-lvm:8", Path: "/data"},
+					{Index: 1, Volume: "local-lvm:8", Path: "/data"},
+				},
+			},
+			wantFields: []string{"net0.gw", "net0.hwaddr", "net0.tag", "net0", "net0.name", "net0.ip", "net0.gw6", "mp1", "mp1.mp"},
+		},
+		{
+			name: "LxcConfig.Validate() restore checks only set fields",
+			clp: LxcConfig{
+				BaseLxcConfig: BaseLxcConfig{Restore: true, OSTemplate: "local:backup/vzdump-lxc-999.tar.lzo", Memory: 8},
+			},
+			wantFields: []string{"memory"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.clp.Validate()
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", err)
+			}
+
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Errorf("LxcConfig.Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			errs, ok := err.(ValidationErrors)
+			if !ok {
+				t.Errorf("LxcConfig.Validate() error = %v, want ValidationErrors", err)
+				return
+			}
+
+			if len(errs) != len(tt.wantFields) {
+				t.Errorf("LxcConfig.Validate() got %d errors, want %d: %v", len(errs), len(tt.wantFields), errs)
+			}
+
+			for _, f := range tt.wantFields {
+				if !errs.Has(f) {
+					t.Errorf("LxcConfig.Validate() no error for field %s: %v", f, errs)
+				}
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package proxmox_test
 
 import (
+	"context"
+	"encoding/json"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 	. "github.com/mrgloba/proxmox-api2/proxmox"
 )
 
@@ -400,3 +403,933 @@ func TestNode_ScanUSB(t *testing.T) {
 		})
 	}
 }
+
+func TestNode_StartAll(t *testing.T) {
+	type args struct {
+		vms   []int64
+		force bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "Node.StartAll() test",
+			args:    args{vms: []int64{TEST_PROXMOX_VMID}, force: true},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+
+			got, err := nodes[0].StartAll(tt.args.vms, tt.args.force)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Node.StartAll() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			_, _, err = got.WaitForStatus("stopped", 60)
+			if err != nil {
+				t.Errorf("Node.StartAll() task error = %v", err)
+				return
+			}
+
+			progress, err := nodes[0].GetBulkProgress(got.UPid)
+			if err != nil {
+				t.Errorf("Node.GetBulkProgress() error = %v", err)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("%v : %v\n", *got, progress)
+			}
+
+			for _, p := range progress {
+				if p.VmId == TEST_PROXMOX_VMID && p.Status == GUEST_PROGRESS_ERROR {
+					t.Errorf("Node.GetBulkProgress() = %v", p)
+				}
+			}
+		})
+	}
+}
+
+func TestParseBulkProgress(t *testing.T) {
+	tests := []struct {
+		name    string
+		taskLog []TaskLogLine
+		want    map[int64]string
+	}{
+		{
+			name: "Bulk start with failed guest",
+			taskLog: []TaskLogLine{
+				{N: 1, T: "Starting VM 100"},
+				{N: 2, T: "Starting CT 101"},
+				{N: 3, T: "Starting VM 102"},
+				{N: 4, T: "Starting VM 102 failed: unable to find configuration file for VM 102 on node 'pve'"},
+				{N: 5, T: "Waiting for 10 seconds (startup delay)"},
+				{N: 6, T: "TASK OK"},
+			},
+			want: map[int64]string{100: GUEST_PROGRESS_OK, 101: GUEST_PROGRESS_OK, 102: GUEST_PROGRESS_ERROR},
+		},
+		{
+			name: "Bulk stop with failed container",
+			taskLog: []TaskLogLine{
+				{N: 1, T: "Stopping CT 101"},
+				{N: 2, T: "Stopping CT 101 failed - got timeout"},
+				{N: 3, T: "Stopping VM 100"},
+			},
+			want: map[int64]string{100: GUEST_PROGRESS_RUNNING, 101: GUEST_PROGRESS_ERROR},
+		},
+		{
+			name: "Bulk migrate with unrecognised lines",
+			taskLog: []TaskLogLine{
+				{N: 1, T: "Migrating VM 100"},
+				{N: 2, T: "Resuming VM 103"},
+				{N: 3, T: "Migrating CT 101"},
+				{N: 4, T: "VM 105 migration failed: no such guest"},
+				{N: 5, T: "some new status wording for VM 100"},
+				{N: 6, T: "CT 101: migration aborted - unable to connect to target"},
+				{N: 7, T: "TASK ERROR: migration problems"},
+			},
+			want: map[int64]string{100: GUEST_PROGRESS_OK, 101: GUEST_PROGRESS_ERROR},
+		},
+		{
+			name: "Unknown task log",
+			taskLog: []TaskLogLine{
+				{N: 1, T: "no guests to start"},
+				{N: 2, T: "TASK OK"},
+			},
+			want: map[int64]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseBulkProgress(tt.taskLog)
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Errorf("ParseBulkProgress() = %v, want %v", got, tt.want)
+				return
+			}
+			for _, p := range got {
+				if p.Status != tt.want[p.VmId] {
+					t.Errorf("ParseBulkProgress() VMID %d status = %s, want %s", p.VmId, p.Status, tt.want[p.VmId])
+				}
+			}
+		})
+	}
+}
+
+func TestPCIDevice_ToQemuHostPCI(t *testing.T) {
+	type args struct {
+		index        int
+		allFunctions bool
+		mdev         string
+	}
+	tests := []struct {
+		name   string
+		device PCIDevice
+		args   args
+		want   string
+	}{
+		{
+			name:   "Single function",
+			device: PCIDevice{Id: "0000:01:00.0"},
+			args:   args{index: 0},
+			want:   "01:00.0",
+		},
+		{
+			name:   "All functions",
+			device: PCIDevice{Id: "0000:01:00.1"},
+			args:   args{index: 1, allFunctions: true},
+			want:   "01:00",
+		},
+		{
+			name:   "Mediated device",
+			device: PCIDevice{Id: "0000:00:02.0", MDev: true},
+			args:   args{index: 2, mdev: "i915-GVTg_V5_4"},
+			want:   "00:02.0,mdev=i915-GVTg_V5_4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hp := tt.device.ToQemuHostPCI(tt.args.index, tt.args.allFunctions, tt.args.mdev)
+			got := hp.String()
+
+			if DEBUG_TESTS {
+				t.Logf("%s\n", got)
+			}
+
+			if got != tt.want || hp.Index != tt.args.index {
+				t.Errorf("PCIDevice.ToQemuHostPCI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUSBDevice_ToQemuUSB(t *testing.T) {
+	tests := []struct {
+		name   string
+		device USBDevice
+		byPort bool
+		want   string
+	}{
+		{
+			name:   "By vendor and product",
+			device: USBDevice{Vendid: "0x046d", Prodid: "0xc52b", Speed: "12"},
+			want:   "host=046d:c52b",
+		},
+		{
+			name:   "By port USB3",
+			device: USBDevice{Busnum: 2, Usbpath: "1.3", Speed: "5000"},
+			byPort: true,
+			want:   "host=2-1.3,usb3=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qu := tt.device.ToQemuUSB(0, tt.byPort)
+			got := qu.String()
+
+			if DEBUG_TESTS {
+				t.Logf("%s\n", got)
+			}
+
+			if got != tt.want {
+				t.Errorf("USBDevice.ToQemuUSB() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNode_GetGuests(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantVmId map[int64]string
+		wantErr  bool
+	}{
+		{
+			name: "Node.GetGuests test",
+			wantVmId: map[int64]string{
+				TEST_PROXMOX_VMID:      GUEST_TYPE_LXC,
+				TEST_PROXMOX_QEMU_VMID: GUEST_TYPE_QEMU,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+
+			got, err := nodes[0].GetGuests()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Node.GetGuests() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			found := make(map[int64]string)
+			for _, g := range got {
+				if DEBUG_TESTS {
+					t.Logf("%d %s %s %v\n", g.GetVmId(), g.GetType(), g.GetName(), g.GetTags())
+				}
+				if g.GetNode() == nil || g.GetNode().Node != nodes[0].Node {
+					t.Errorf("Node.GetGuests() guest %d has wrong node", g.GetVmId())
+				}
+				found[g.GetVmId()] = g.GetType()
+			}
+
+			for vmid, guestType := range tt.wantVmId {
+				if found[vmid] != guestType {
+					t.Errorf("Node.GetGuests() guest %d = %s, want %s", vmid, found[vmid], guestType)
+				}
+			}
+		})
+	}
+}
+
+func TestNode_GetStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{ name: "Node.GetStatus test", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+			got, err := nodes[0].GetStatus()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Node.GetStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			if len(got.LoadAvg) != 3 || got.CPUInfo.Cpus == 0 || got.Memory.Total == 0 || len(got.PVEVersion) == 0 {
+				t.Errorf("Node.GetStatus() = %v", got)
+			}
+		})
+	}
+}
+
+func TestNode_GetRRDData(t *testing.T) {
+	type args struct {
+		timeframe string
+		cf        string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{ name: "Node.GetRRDData hour average", args: args{timeframe: RRD_TIMEFRAME_HOUR, cf: RRD_CF_AVERAGE}, wantErr: false},
+		{ name: "Node.GetRRDData wrong timeframe", args: args{timeframe: "decade"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+			got, err := nodes[0].GetRRDData(tt.args.timeframe, tt.args.cf)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Node.GetRRDData() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if DEBUG_TESTS && len(got) > 0 {
+				t.Logf("%d points, last: %v\n", len(got), got[len(got)-1])
+			}
+
+			if !tt.wantErr && len(got) == 0 {
+				t.Errorf("Node.GetRRDData() = %v", got)
+			}
+		})
+	}
+}
+
+func TestNetworkInterface_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		ni         NetworkInterface
+		wantFields []string
+	}{
+		{
+			name: "Valid bridge",
+			ni:   NetworkInterface{Iface: "vmbr1", Type: NETWORK_TYPE_BRIDGE, CIDR: "10.0.0.2/24", Gateway: "10.0.0.1", BridgePorts: "bond0", BridgeVlanAware: true, BridgeVids: "2-4094"},
+		},
+		{
+			name: "Valid bond",
+			ni:   NetworkInterface{Iface: "bond0", Type: NETWORK_TYPE_BOND, Slaves: "eno1 eno2", BondMode: BOND_MODE_8023AD, BondXmitHashPolicy: "layer3+4"},
+		},
+		{
+			name: "Valid vlan",
+			ni:   NetworkInterface{Iface: "vmbr1.100", Type: NETWORK_TYPE_VLAN, CIDR: "192.168.100.2/24"},
+		},
+		{
+			name:       "Bridge with bad address and own port",
+			ni:         NetworkInterface{Iface: "vmbr1", Type: NETWORK_TYPE_BRIDGE, CIDR: "10.0.0.2", Gateway: "10.0.0.300", BridgePorts: "vmbr1"},
+			wantFields: []string{"cidr", "gateway", "bridge_ports"},
+		},
+		{
+			name:       "Bond without slaves and wrong policy",
+			ni:         NetworkInterface{Iface: "bond0", Type: NETWORK_TYPE_BOND, BondMode: BOND_MODE_ACTIVE_BACKUP, BondXmitHashPolicy: "layer2"},
+			wantFields: []string{"slaves", "bond_xmit_hash_policy"},
+		},
+		{
+			name:       "Vlan without raw device",
+			ni:         NetworkInterface{Iface: "vlan100", Type: NETWORK_TYPE_VLAN, VlanId: 100},
+			wantFields: []string{"vlan-raw-device"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ni.Validate()
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", err)
+			}
+
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Errorf("NetworkInterface.Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			errs, ok := err.(ValidationErrors)
+			if !ok {
+				t.Errorf("NetworkInterface.Validate() error = %v, want ValidationErrors", err)
+				return
+			}
+			for _, f := range tt.wantFields {
+				if !errs.Has(f) {
+					t.Errorf("NetworkInterface.Validate() missing error for %s: %v", f, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestNode_GetNetworkInterfaces(t *testing.T) {
+	tests := []struct {
+		name      string
+		ifaceType string
+		wantErr   bool
+	}{
+		{ name: "Node.GetNetworkInterfaces bridges", ifaceType: NETWORK_TYPE_BRIDGE, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+			got, err := nodes[0].GetNetworkInterfaces(tt.ifaceType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Node.GetNetworkInterfaces() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			for _, ni := range got {
+				if ni.Type != tt.ifaceType {
+					t.Errorf("Node.GetNetworkInterfaces() = %v, want type %s", ni, tt.ifaceType)
+				}
+			}
+		})
+	}
+}
+
+func TestParseHosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []HostsEntry
+		lookup  map[string][]string
+	}{
+		{
+			name:    "Parse hosts file",
+			content: "127.0.0.1 localhost.localdomain localhost\n10.0.0.2 pve.example.com pve # node address\n\n# IPv6\n::1 ip6-localhost ip6-loopback\n",
+			want: []HostsEntry{
+				{IP: "127.0.0.1", Names: []string{"localhost.localdomain", "localhost"}},
+				{IP: "10.0.0.2", Names: []string{"pve.example.com", "pve"}, Comment: " node address"},
+				{Names: []string{}},
+				{Comment: " IPv6", Names: nil},
+				{IP: "::1", Names: []string{"ip6-localhost", "ip6-loopback"}},
+			},
+			lookup: map[string][]string{"pve": {"10.0.0.2"}, "ip6-loopback": {"::1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseHosts(tt.content)
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Errorf("ParseHosts() = %v, want %v", got, tt.want)
+				return
+			}
+			for i := range got {
+				if got[i].IP != tt.want[i].IP || got[i].Comment != tt.want[i].Comment || strings.Join(got[i].Names, " ") != strings.Join(tt.want[i].Names, " ") {
+					t.Errorf("ParseHosts() line %d = %v, want %v", i+1, got[i], tt.want[i])
+				}
+			}
+
+			hosts := NodeHosts{Entries: got}
+			if err := hosts.Validate(); err != nil {
+				t.Errorf("NodeHosts.Validate() error = %v", err)
+			}
+			for name, ips := range tt.lookup {
+				if strings.Join(hosts.Lookup(name), ",") != strings.Join(ips, ",") {
+					t.Errorf("NodeHosts.Lookup(%s) = %v, want %v", name, hosts.Lookup(name), ips)
+				}
+			}
+
+			if hosts.String() != tt.content {
+				t.Errorf("NodeHosts.String() = %q, want %q", hosts.String(), tt.content)
+			}
+		})
+	}
+}
+
+func TestNode_GetDNS(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{ name: "Node.GetDNS test", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+			got, err := nodes[0].GetDNS()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Node.GetDNS() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			if len(got.Servers()) == 0 {
+				t.Errorf("Node.GetDNS() = %v", got)
+			}
+
+			nodeTime, err := nodes[0].GetTime()
+			if err != nil {
+				t.Errorf("Node.GetTime() error = %v", err)
+				return
+			}
+			if nodeTime.UTC().IsZero() || len(nodeTime.Timezone) == 0 {
+				t.Errorf("Node.GetTime() = %v", nodeTime)
+			}
+		})
+	}
+}
+
+func TestParseJournal(t *testing.T) {
+	tests := []struct {
+		name            string
+		lines           []string
+		wantStartCursor string
+		wantEndCursor   string
+		wantLines       []string
+	}{
+		{
+			name:            "Journal with entries",
+			lines:           []string{"s=1;i=10", "Oct 19 10:00:00 pve pveproxy[1]: starting", "Oct 19 10:00:01 pve pveproxy[1]: started", "s=1;i=11"},
+			wantStartCursor: "s=1;i=10",
+			wantEndCursor:   "s=1;i=11",
+			wantLines:       []string{"Oct 19 10:00:00 pve pveproxy[1]: starting", "Oct 19 10:00:01 pve pveproxy[1]: started"},
+		},
+		{
+			name:            "Empty journal",
+			lines:           []string{},
+			wantLines:       []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseJournal(tt.lines)
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			if got.StartCursor != tt.wantStartCursor || got.EndCursor != tt.wantEndCursor {
+				t.Errorf("ParseJournal() cursors = %s %s, want %s %s", got.StartCursor, got.EndCursor, tt.wantStartCursor, tt.wantEndCursor)
+			}
+			if strings.Join(got.Lines, "\n") != strings.Join(tt.wantLines, "\n") {
+				t.Errorf("ParseJournal() lines = %v, want %v", got.Lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestPollJournal(t *testing.T) {
+	entries := [][2]string{
+		{"s=1;i=1", "Oct 19 10:00:00 pve systemd[1]: Started cron.service"},
+		{"s=1;i=2", "Oct 19 10:00:01 pve CRON[2]: pam_unix(cron:session): session opened"},
+		{"s=1;i=3", "Oct 19 10:00:01 pve CRON[2]: pam_unix(cron:session): session opened"},
+		{"s=1;i=4", "Oct 19 10:00:02 pve pvedaemon[3]: <root@pam> successful auth"},
+		{"s=1;i=5", "Oct 19 10:00:01 pve CRON[2]: pam_unix(cron:session): session opened"},
+	}
+
+	tests := []struct {
+		name      string
+		opts      NodeJournalOptions
+		wantLines []string
+	}{
+		{
+			name:      "PollJournal() from the beginning",
+			opts:      NodeJournalOptions{},
+			wantLines: []string{entries[0][1], entries[1][1], entries[2][1], entries[3][1], entries[4][1]},
+		},
+		{
+			name:      "PollJournal() from a start cursor",
+			opts:      NodeJournalOptions{StartCursor: "s=1;i=3"},
+			wantLines: []string{entries[2][1], entries[3][1], entries[4][1]},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every poll makes two more entries available, like journalctl the
+			// answer starts with the entry at startcursor.
+			available := 0
+			fetch := func(opts NodeJournalOptions) (*NodeJournal, error) {
+				available += 2
+				if available > len(entries) {
+					available = len(entries)
+				}
+
+				start := 0
+				for i, e := range entries {
+					if e[0] == opts.StartCursor {
+						start = i
+					}
+				}
+				if start >= available {
+					return &NodeJournal{}, nil
+				}
+
+				journal := NodeJournal{StartCursor: entries[start][0], EndCursor: entries[available-1][0]}
+				for _, e := range entries[start:available] {
+					journal.Lines = append(journal.Lines, e[1])
+				}
+				return &journal, nil
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			lines := make(chan string)
+			done := make(chan error, 1)
+			go func() {
+				done <- PollJournal(ctx, fetch, tt.opts, time.Millisecond, lines)
+			}()
+
+			var got []string
+			for len(got) < len(tt.wantLines) {
+				select {
+				case l := <-lines:
+					got = append(got, l)
+				case <-time.After(time.Second):
+					t.Fatalf("PollJournal() lines = %v, want %v", got, tt.wantLines)
+				}
+			}
+
+			select {
+			case l := <-lines:
+				got = append(got, l)
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			cancel()
+			if err := <-done; err != context.Canceled {
+				t.Errorf("PollJournal() error = %v, want %v", err, context.Canceled)
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			if strings.Join(got, "\n") != strings.Join(tt.wantLines, "\n") {
+				t.Errorf("PollJournal() lines = %v, want %v", got, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestNodeSyslogOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    NodeSyslogOptions
+		wantErr bool
+	}{
+		{ name: "Valid options", opts: NodeSyslogOptions{Limit: 50, Since: "2026-10-19 10:00", Service: "pveproxy"}, wantErr: false},
+		{ name: "Wrong since", opts: NodeSyslogOptions{Since: "yesterday"}, wantErr: true},
+		{ name: "Wrong service", opts: NodeSyslogOptions{Service: "pve proxy"}, wantErr: true},
+		{ name: "Negative limit", opts: NodeSyslogOptions{Limit: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if DEBUG_TESTS && err != nil {
+				t.Log(err.Error())
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NodeSyslogOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateServiceName(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		wantErr bool
+	}{
+		{ name: "pveproxy", service: "pveproxy", wantErr: false},
+		{ name: "corosync", service: "corosync", wantErr: false},
+		{ name: "chrony", service: "chrony", wantErr: false},
+		{ name: "Unknown service", service: "nginx", wantErr: true},
+		{ name: "Path injection", service: "pveproxy/../sshd", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateServiceName(tt.service)
+			if DEBUG_TESTS && err != nil {
+				t.Log(err.Error())
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateServiceName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNode_Services(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{ name: "Node.Services test", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+			got, err := nodes[0].Services()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Node.Services() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			for _, s := range got {
+				if ValidateServiceName(s.Service) != nil {
+					t.Errorf("Node.Services() returned unknown service %s", s.Service)
+				}
+			}
+		})
+	}
+}
+
+func TestAptRepositories_StandardRepository(t *testing.T) {
+	repositories := AptRepositories{
+		StandardRepos: []AptStandardRepository{
+			{Handle: APT_REPO_ENTERPRISE, Name: "Enterprise", Status: true},
+			{Handle: APT_REPO_NO_SUBSCRIPTION, Name: "No-Subscription"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		handle     string
+		wantFound  bool
+		wantStatus bool
+	}{
+		{ name: "Enabled repository", handle: APT_REPO_ENTERPRISE, wantFound: true, wantStatus: true},
+		{ name: "Disabled repository", handle: APT_REPO_NO_SUBSCRIPTION, wantFound: true, wantStatus: false},
+		{ name: "Unknown repository", handle: APT_REPO_TEST, wantFound: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := repositories.StandardRepository(tt.handle)
+			if (got != nil) != tt.wantFound {
+				t.Errorf("AptRepositories.StandardRepository() = %v, wantFound %v", got, tt.wantFound)
+				return
+			}
+			if got != nil && bool(got.Status) != tt.wantStatus {
+				t.Errorf("AptRepositories.StandardRepository() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNode_GetAptVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{ name: "Node.GetAptVersions test", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+			got, err := nodes[0].GetAptVersions()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Node.GetAptVersions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			found := false
+			for _, p := range got {
+				if p.Package == "pve-manager" && len(p.Version) > 0 {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Node.GetAptVersions() has no pve-manager version")
+			}
+
+			repositories, err := nodes[0].GetAptRepositories()
+			if err != nil {
+				t.Errorf("Node.GetAptRepositories() error = %v", err)
+				return
+			}
+			if len(repositories.Digest) == 0 {
+				t.Errorf("Node.GetAptRepositories() has no digest")
+			}
+		})
+	}
+}
+
+func TestZFSPoolOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ZFSPoolOptions
+		wantErr bool
+	}{
+		{ name: "Single disk", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_SINGLE, Devices: []string{"/dev/sdb"}}, wantErr: false},
+		{ name: "Mirror with ashift and compression", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_MIRROR, Devices: []string{"/dev/sdb", "/dev/sdc"}, Ashift: 12, Compression: ZFS_COMPRESSION_LZ4, AddStorage: true}, wantErr: false},
+		{ name: "Mirror with one disk", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_MIRROR, Devices: []string{"/dev/sdb"}}, wantErr: true},
+		{ name: "Raidz with 2 disks", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_RAIDZ, Devices: []string{"/dev/sdb", "/dev/sdc"}}, wantErr: true},
+		{ name: "Raidz with 3 disks", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_RAIDZ, Devices: []string{"/dev/sdb", "/dev/sdc", "/dev/sdd"}}, wantErr: false},
+		{ name: "Raidz2 with 3 disks", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_RAIDZ2, Devices: []string{"/dev/sdb", "/dev/sdc", "/dev/sdd"}}, wantErr: true},
+		{ name: "Raidz2 with 4 disks", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_RAIDZ2, Devices: []string{"/dev/sdb", "/dev/sdc", "/dev/sdd", "/dev/sde"}}, wantErr: false},
+		{ name: "Raidz3 with 4 disks", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_RAIDZ3, Devices: []string{"/dev/sdb", "/dev/sdc", "/dev/sdd", "/dev/sde"}}, wantErr: true},
+		{ name: "Raidz3 with 5 disks", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_RAIDZ3, Devices: []string{"/dev/sdb", "/dev/sdc", "/dev/sdd", "/dev/sde", "/dev/sdf"}}, wantErr: false},
+		{ name: "Raid10 with 2 disks", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_RAID10, Devices: []string{"/dev/sdb", "/dev/sdc"}}, wantErr: true},
+		{ name: "Raid10 with 4 disks", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_RAID10, Devices: []string{"/dev/sdb", "/dev/sdc", "/dev/sdd", "/dev/sde"}}, wantErr: false},
+		{ name: "Raid10 with odd disks", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_RAID10, Devices: []string{"/dev/sdb", "/dev/sdc", "/dev/sdd", "/dev/sde", "/dev/sdf"}}, wantErr: true},
+		{ name: "Wrong ashift", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_SINGLE, Devices: []string{"/dev/sdb"}, Ashift: 20}, wantErr: true},
+		{ name: "Wrong raid level", opts: ZFSPoolOptions{Name: "tank", RaidLevel: "raid5", Devices: []string{"/dev/sdb", "/dev/sdc"}}, wantErr: true},
+		{ name: "Wrong device", opts: ZFSPoolOptions{Name: "tank", RaidLevel: ZFS_RAID_SINGLE, Devices: []string{"sdb"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if DEBUG_TESTS && err != nil {
+				t.Log(err.Error())
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ZFSPoolOptions.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiskSmart_Decode(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           string
+		wantHealthy    bool
+		wantAttributes int
+		wantValue      PVEInt
+		wantThreshold  PVEInt
+	}{
+		{
+			name: "ATA disk with attributes",
+			data: `{"type":"ata","health":"PASSED","attributes":[` +
+				`{"id":"  5","name":"Reallocated_Sector_Ct","value":100,"worst":100,"threshold":10,"fail":"-","flags":"PO--CK","raw":"0","normalized":100},` +
+				`{"id":"  9","name":"Power_On_Hours","value":97,"worst":97,"threshold":0,"fail":"-","flags":"-O--CK","raw":"14235","normalized":97},` +
+				`{"id":"177","name":"Wear_Leveling_Count","value":95,"worst":95,"threshold":0,"fail":"-","flags":"PO--C-","raw":"52","normalized":95}]}`,
+			wantHealthy:    true,
+			wantAttributes: 3,
+			wantValue:      100,
+			wantThreshold:  10,
+		},
+		{
+			name:           "NVMe disk text output",
+			data:           `{"type":"text","health":"OK","text":"Critical Warning: 0x00\nPercentage Used: 2%\n"}`,
+			wantHealthy:    true,
+			wantAttributes: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got DiskSmart
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			if got.IsHealthy() != tt.wantHealthy {
+				t.Errorf("DiskSmart.IsHealthy() = %v, want %v", got.IsHealthy(), tt.wantHealthy)
+			}
+			if len(got.Attributes) != tt.wantAttributes {
+				t.Errorf("DiskSmart.Attributes = %v, want %d attributes", got.Attributes, tt.wantAttributes)
+				return
+			}
+			if len(got.Attributes) > 0 && (got.Attributes[0].Value != tt.wantValue || got.Attributes[0].Threshold != tt.wantThreshold) {
+				t.Errorf("DiskSmart.Attributes[0] = %v, want value %d threshold %d", got.Attributes[0], tt.wantValue, tt.wantThreshold)
+			}
+		})
+	}
+}
+
+func TestNode_GetDisks(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{ name: "Node.GetDisks test", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+			got, err := nodes[0].GetDisks("", false, false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Node.GetDisks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			if len(got) == 0 {
+				t.Errorf("Node.GetDisks() returned no disks")
+				return
+			}
+
+			smart, err := nodes[0].GetDiskSmart(got[0].DevPath, false)
+			if err != nil {
+				t.Errorf("Node.GetDiskSmart() error = %v", err)
+				return
+			}
+			if DEBUG_TESTS {
+				t.Logf("%v\n", smart)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 const (
 	TEST_PROXMOX_VMID = 999
+	TEST_PROXMOX_QEMU_VMID = 998
 	TEST_PROXMOX_HOST  = "localhost"
 	TEST_PROXMOX_PORT  = "8006"
 	TEST_PROXMOX_USER  = "testuser"
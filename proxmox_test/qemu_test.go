@@ -0,0 +1,911 @@
+package proxmox_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	. "github.com/mrgloba/proxmox-api2/proxmox"
+)
+
+func TestNode_GetQemuList(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{
+			name:    "GetQemuList",
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+			got, err := nodes[0].GetQemuList()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Node.GetQemuList() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("QemuList: %v\n", got)
+			}
+
+			if len(got) <= 0 {
+				t.Errorf("Node.GetQemuList() error = %v, wantErr %v", "not vms received", got)
+			}
+		})
+	}
+}
+
+func TestQemu_Start(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{
+			name:    "Qemu.Start() test",
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+
+			qemu, err := nodes[0].GetQemu(TEST_PROXMOX_QEMU_VMID)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			got, err := qemu.Start(false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Qemu.Start() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("Qemu: %v\n", *got)
+			}
+
+			if strings.Index(string(got.UPid), nodes[0].Node) <= 0 {
+				t.Errorf("Qemu.Start() = %v", got)
+			}
+		})
+	}
+}
+
+func TestQemu_GetStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Qemu.GetStatus() test",
+			want:    QEMU_STATUS_RUNNING,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+
+			qemu, err := nodes[0].GetQemu(TEST_PROXMOX_QEMU_VMID)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			ok, got, err := qemu.WaitForStatus(tt.want, 30)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Qemu.WaitForStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("QemuStatus: %v\n", got)
+			}
+
+			if !ok || got.QmpStatus != QEMU_QMPSTATUS_RUNNING {
+				t.Errorf("Qemu.GetStatus() = %v", got)
+			}
+		})
+	}
+}
+
+func TestQemuDisk_SetFromString(t *testing.T) {
+	type args struct {
+		bus string
+		idx int
+		str string
+	}
+	tests := []struct {
+		name string
+		args args
+		want QemuDisk
+	}{
+		{
+			name: "QemuDisk.SetFromString() test1",
+			args: args{bus: QEMU_BUS_SCSI, idx: 0, str: "local-lvm:vm-100-disk-0,cache=writeback,discard=on,iothread=1,size=32G"},
+			want: QemuDisk{Bus: QEMU_BUS_SCSI, Index: 0, Volume: "local-lvm:vm-100-disk-0", Cache: "writeback", Discard: true, IOThread: true, Size: "32G"},
+		},
+		{
+			name: "QemuDisk.SetFromString() test2",
+			args: args{bus: QEMU_BUS_IDE, idx: 2, str: "local:iso/debian.iso,media=cdrom"},
+			want: QemuDisk{Bus: QEMU_BUS_IDE, Index: 2, Volume: "local:iso/debian.iso", Media: "cdrom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qd := QemuDisk{}
+			qd.SetFromString(tt.args.bus, tt.args.idx, tt.args.str)
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", qd)
+			}
+
+			if !reflect.DeepEqual(qd, tt.want) {
+				t.Errorf("QemuDisk.SetFromString() = %v, want %v", qd, tt.want)
+			}
+		})
+	}
+}
+
+func TestQemuNetwork_SetFromString(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		want QemuNetwork
+	}{
+		{
+			name: "e1000e with MAC",
+			str:  "e1000e=AA:BB:CC:00:00:01,bridge=vmbr0",
+			want: QemuNetwork{Model: QEMU_NET_MODEL_E1000E, MACAddr: "AA:BB:CC:00:00:01", Bridge: "vmbr0"},
+		},
+		{
+			name: "i82551 with MAC",
+			str:  "i82551=AA:BB:CC:00:00:02,bridge=vmbr0,firewall=1",
+			want: QemuNetwork{Model: QEMU_NET_MODEL_I82551, MACAddr: "AA:BB:CC:00:00:02", Bridge: "vmbr0", Firewall: true},
+		},
+		{
+			name: "ne2k_pci with MAC",
+			str:  "ne2k_pci=AA:BB:CC:00:00:03,bridge=vmbr1,tag=20",
+			want: QemuNetwork{Model: QEMU_NET_MODEL_NE2K_PCI, MACAddr: "AA:BB:CC:00:00:03", Bridge: "vmbr1", Tag: 20},
+		},
+		{
+			name: "e1000-82545em with MAC",
+			str:  "e1000-82545em=AA:BB:CC:00:00:04,bridge=vmbr0",
+			want: QemuNetwork{Model: QEMU_NET_MODEL_E1000_82545EM, MACAddr: "AA:BB:CC:00:00:04", Bridge: "vmbr0"},
+		},
+		{
+			name: "pcnet without MAC",
+			str:  "pcnet,bridge=vmbr0",
+			want: QemuNetwork{Model: QEMU_NET_MODEL_PCNET, Bridge: "vmbr0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got QemuNetwork
+			got.SetFromString(0, tt.str)
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", got)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QemuNetwork.SetFromString() = %v, want %v", got, tt.want)
+			}
+
+			if got.String() != tt.str {
+				t.Errorf("QemuNetwork.String() = %s, want %s", got.String(), tt.str)
+			}
+
+			var qc QemuConfig
+			qc.Name = "test"
+			qc.Networks = []QemuNetwork{got}
+			if err := qc.Validate(); err != nil && strings.Contains(err.Error(), "model") {
+				t.Errorf("QemuConfig.Validate() model error = %v", err)
+			}
+		})
+	}
+}
+
+func TestQemuNetwork_String(t *testing.T) {
+	tests := []struct {
+		name string
+		qn   QemuNetwork
+		want string
+	}{
+		{
+			name: "QemuNetwork.String() test1",
+			qn:   QemuNetwork{Model: "virtio", MACAddr: "BC:24:11:00:00:01", Bridge: "vmbr0", Tag: 10, Firewall: true},
+			want: "virtio=BC:24:11:00:00:01,bridge=vmbr0,firewall=1,tag=10",
+		},
+		{
+			name: "QemuNetwork.String() test2",
+			qn:   QemuNetwork{Model: "e1000", Bridge: "vmbr1"},
+			want: "e1000,bridge=vmbr1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.qn.String()
+
+			if DEBUG_TESTS {
+				t.Logf("%s\n", got)
+			}
+
+			var qn QemuNetwork
+			qn.SetFromString(tt.qn.Index, got)
+			if !reflect.DeepEqual(qn, tt.qn) {
+				t.Errorf("QemuNetwork.SetFromString(String()) = %v, want %v", qn, tt.qn)
+			}
+		})
+	}
+}
+
+func TestQemuConfigReceiver_Parse(t *testing.T) {
+	tests := []struct {
+		name     string
+		qcr      QemuConfigReceiver
+		testFunc func(qc *QemuConfig) bool
+	}{
+		{
+			name: "QemuConfigReceiver.Parse() test1",
+			qcr: QemuConfigReceiver{
+				"name":    "test",
+				"cores":   float64(2),
+				"memory":  "2048",
+				"cpu":     "host,flags=+aes",
+				"boot":    "order=scsi0;net0",
+				"scsi0":   "local-lvm:vm-100-disk-0,size=32G",
+				"net10":   "virtio=BC:24:11:00:00:02,bridge=vmbr0",
+				"net2":    "virtio=BC:24:11:00:00:01,bridge=vmbr0",
+				"serial0": "socket",
+				"unused0": "local-lvm:vm-100-disk-1",
+			},
+			testFunc: func(qc *QemuConfig) bool {
+				return qc.Name == "test" && qc.Cores == 2 && qc.Memory == 2048 &&
+					qc.CPU.Type == "host" && len(qc.BootOrder) == 2 &&
+					len(qc.Disks) == 1 && qc.Disks[0].Size == "32G" &&
+					len(qc.Networks) == 2 && qc.Networks[0].Index == 2 && qc.Networks[1].Index == 10 &&
+					qc.Serials[0] == "socket" && qc.Unused[0] == "local-lvm:vm-100-disk-1"
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.qcr.Parse()
+			if err != nil {
+				t.Errorf("QemuConfigReceiver.Parse() error = %v", err)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", *got)
+			}
+
+			if !tt.testFunc(got) {
+				t.Errorf("QemuConfigReceiver.Parse() = %v", *got)
+			}
+		})
+	}
+}
+
+func TestQemuConfig_Validate(t *testing.T) {
+	valid := func() QemuConfig {
+		qc := QemuConfig{
+			Disks:     []QemuDisk{{Bus: QEMU_BUS_SCSI, Index: 0, Volume: "local-lvm:32", IOThread: true}},
+			Networks:  []QemuNetwork{{Index: 0, Model: "virtio", Bridge: "vmbr0"}},
+			BootOrder: []string{"scsi0", "net0"},
+		}
+		qc.VmId = TEST_PROXMOX_QEMU_VMID
+		qc.Name = "test-vm"
+		qc.Cores = 2
+		qc.Memory = 2048
+		return qc
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(qc *QemuConfig)
+		wantFields []string
+	}{
+		{
+			name:   "Valid config",
+			modify: func(qc *QemuConfig) {},
+		},
+		{
+			name: "Balloon greater than memory",
+			modify: func(qc *QemuConfig) {
+				qc.Balloon = 4096
+			},
+			wantFields: []string{"balloon"},
+		},
+		{
+			name: "Bad disk and network",
+			modify: func(qc *QemuConfig) {
+				qc.Disks = append(qc.Disks, QemuDisk{Bus: QEMU_BUS_IDE, Index: 4, Volume: "local-lvm:8", IOThread: true})
+				qc.Networks[0].Tag = 5000
+			},
+			wantFields: []string{"ide4", "net0.tag"},
+		},
+		{
+			name: "EFI disk without OVMF",
+			modify: func(qc *QemuConfig) {
+				qc.EFIDisk = &EFIDisk{Volume: "local-lvm:1"}
+				qc.BootOrder = append(qc.BootOrder, "virtio7")
+			},
+			wantFields: []string{"efidisk0", "boot"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qc := valid()
+			tt.modify(&qc)
+
+			err := qc.Validate()
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", err)
+			}
+
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Errorf("QemuConfig.Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			errs, ok := err.(ValidationErrors)
+			if !ok {
+				t.Errorf("QemuConfig.Validate() error = %v, want ValidationErrors", err)
+				return
+			}
+			for _, f := range tt.wantFields {
+				if !errs.Has(f) {
+					t.Errorf("QemuConfig.Validate() missing error for %s: %v", f, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestQemuConfig_CloudInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		ci       CloudInitConfig
+		wantKeys map[string]string
+	}{
+		{
+			name: "Cloud-init url data and parse",
+			ci: CloudInitConfig{
+				User:          "admin",
+				SSHKeys:       []string{"ssh-ed25519 AAAAC3Nza+/key= admin@host", "ssh-rsa AAAAB3Nza user"},
+				IPConfigs:     []QemuIPConfig{{Index: 0, IP: "192.168.1.10/24", GW: "192.168.1.1", IP6: "auto"}},
+				Nameservers:   []string{"1.1.1.1", "8.8.8.8"},
+				SearchDomains: []string{"example.com"},
+				Custom:        CloudInitCustom{User: "local:snippets/user.yaml"},
+			},
+			wantKeys: map[string]string{
+				"ciuser":       "admin",
+				"sshkeys":      "ssh-ed25519%20AAAAC3Nza%2B%2Fkey%3D%20admin%40host%0Assh-rsa%20AAAAB3Nza%20user",
+				"ipconfig0":    "ip=192.168.1.10/24,gw=192.168.1.1,ip6=auto",
+				"nameserver":   "1.1.1.1 8.8.8.8",
+				"searchdomain": "example.com",
+				"cicustom":     "user=local:snippets/user.yaml",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qc := QemuConfig{CloudInit: tt.ci}
+			data := qc.GetUrlDataValues()
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", data)
+			}
+
+			qcr := QemuConfigReceiver{}
+			for k, v := range tt.wantKeys {
+				if data.Get(k) != v {
+					t.Errorf("QemuConfig.GetUrlDataValues() %s = %s, want %s", k, data.Get(k), v)
+				}
+				qcr[k] = data.Get(k)
+			}
+
+			got, err := qcr.Parse()
+			if err != nil {
+				t.Errorf("QemuConfigReceiver.Parse() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got.CloudInit, tt.ci) {
+				t.Errorf("QemuConfigReceiver.Parse() = %v, want %v", got.CloudInit, tt.ci)
+			}
+
+			var errs ValidationErrors
+			if err := (&QemuConfig{CloudInit: tt.ci}).Validate(); err != nil {
+				errs = err.(ValidationErrors)
+			}
+			for _, f := range []string{"ciuser", "sshkeys", "ipconfig0", "ipconfig0.ip", "nameserver", "searchdomain", "cicustom"} {
+				if errs.Has(f) {
+					t.Errorf("QemuConfig.Validate() unexpected error for %s: %v", f, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestQemuAgent_Run(t *testing.T) {
+	tests := []struct {
+		name         string
+		command      []string
+		inputData    string
+		wantExitCode int64
+		wantOut      string
+		wantErr      bool
+	}{
+		{
+			name:         "QemuAgent.Run() echo",
+			command:      []string{"echo", "hello"},
+			wantExitCode: 0,
+			wantOut:      "hello\n",
+			wantErr:      false,
+		},
+		{
+			name:         "QemuAgent.RunWithInput() cat",
+			command:      []string{"cat"},
+			inputData:    "from stdin",
+			wantExitCode: 0,
+			wantOut:      "from stdin",
+			wantErr:      false,
+		},
+		{
+			name:         "QemuAgent.Run() exit code",
+			command:      []string{"sh", "-c", "exit 3"},
+			wantExitCode: 3,
+			wantErr:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+
+			qemu, err := nodes[0].GetQemu(TEST_PROXMOX_QEMU_VMID)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			err = qemu.Agent().Ping()
+			if err != nil {
+				t.Errorf("QemuAgent.Ping() error = %v", err)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			got, err := qemu.Agent().RunWithInput(ctx, tt.inputData, tt.command...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QemuAgent.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("QemuAgentExecStatus: %v\n", got)
+			}
+
+			if int64(got.ExitCode) != tt.wantExitCode || got.OutData != tt.wantOut {
+				t.Errorf("QemuAgent.Run() = %v, want exitcode %d out %q", got, tt.wantExitCode, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestQemu_Snapshot(t *testing.T) {
+	tests := []struct {
+		name     string
+		snapname string
+		vmstate  bool
+		wantErr  bool
+	}{
+		{
+			name:     "Qemu.Snapshot() disk only",
+			snapname: "test_disk",
+			vmstate:  false,
+			wantErr:  false,
+		},
+		{
+			name:     "Qemu.Snapshot() with RAM",
+			snapname: "test_ram",
+			vmstate:  true,
+			wantErr:  false,
+		},
+		{
+			name:     "Qemu.Snapshot() reserved name",
+			snapname: QEMU_SNAPSHOT_CURRENT,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+
+			qemu, err := nodes[0].GetQemu(TEST_PROXMOX_QEMU_VMID)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			task, err := qemu.Snapshot(tt.snapname, "test snapshot", tt.vmstate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Qemu.Snapshot() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			ok, _, err := task.WaitForStatus("stopped", 120)
+			if !ok || err != nil {
+				t.Errorf("Qemu.Snapshot() task error = %v", err)
+				return
+			}
+
+			tree, err := qemu.GetSnapshotTree()
+			if err != nil {
+				t.Errorf("Qemu.GetSnapshotTree() error = %v", err)
+				return
+			}
+
+			var find func(list []*QemuSnapshot) *QemuSnapshot
+			find = func(list []*QemuSnapshot) *QemuSnapshot {
+				for _, s := range list {
+					if s.Name == tt.snapname {
+						return s
+					}
+					if f := find(s.Children); f != nil {
+						return f
+					}
+				}
+				return nil
+			}
+
+			got := find(tree)
+			if DEBUG_TESTS {
+				t.Logf("QemuSnapshot: %v\n", got)
+			}
+			if got == nil || bool(got.VMState) != tt.vmstate {
+				t.Errorf("Qemu.GetSnapshotTree() snapshot %s = %v", tt.snapname, got)
+			}
+
+			_, err = qemu.GetSnapshotConfig(tt.snapname)
+			if err != nil {
+				t.Errorf("Qemu.GetSnapshotConfig() error = %v", err)
+			}
+
+			task, err = qemu.DeleteSnapshot(tt.snapname, false)
+			if err != nil {
+				t.Errorf("Qemu.DeleteSnapshot() error = %v", err)
+				return
+			}
+			task.WaitForStatus("stopped", 120)
+		})
+	}
+}
+
+func TestQemu_CheckMigrate(t *testing.T) {
+	tests := []struct {
+		name       string
+		targetNode string
+		wantErr    bool
+	}{
+		{
+			name:       "Qemu.CheckMigrate() to same node",
+			targetNode: "",
+			wantErr:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+
+			qemu, err := nodes[0].GetQemu(TEST_PROXMOX_QEMU_VMID)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			got, err := qemu.CheckMigrate(tt.targetNode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Qemu.CheckMigrate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("QemuMigratePreconditions: %v\n", got)
+			}
+
+			for _, n := range got.AllowedNodes {
+				if n == TEST_PROXMOX_NODE {
+					t.Errorf("Qemu.CheckMigrate() source node in allowed nodes: %v", got.AllowedNodes)
+				}
+			}
+
+			_, err = qemu.Migrate(TEST_PROXMOX_NODE, QemuMigrateOptions{Online: true})
+			if err == nil {
+				t.Errorf("Qemu.Migrate() to source node error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestQemuMigratePreconditions_Check(t *testing.T) {
+	tests := []struct {
+		name          string
+		preconditions QemuMigratePreconditions
+		targetNode    string
+		opts          QemuMigrateOptions
+		wantErr       bool
+	}{
+		{
+			name:          "Running VM online migration, allowed_nodes not reported",
+			preconditions: QemuMigratePreconditions{Running: true},
+			targetNode:    "pve2",
+			opts:          QemuMigrateOptions{Online: true},
+			wantErr:       false,
+		},
+		{
+			name:          "Running VM without online",
+			preconditions: QemuMigratePreconditions{Running: true},
+			targetNode:    "pve2",
+			opts:          QemuMigrateOptions{},
+			wantErr:       true,
+		},
+		{
+			name:          "Stopped VM to allowed node",
+			preconditions: QemuMigratePreconditions{AllowedNodes: []string{"pve2", "pve3"}},
+			targetNode:    "pve3",
+			opts:          QemuMigrateOptions{},
+			wantErr:       false,
+		},
+		{
+			name:          "Stopped VM to not allowed node",
+			preconditions: QemuMigratePreconditions{AllowedNodes: []string{"pve2"}, NotAllowedNodes: map[string]QemuMigrateNodeBlocker{"pve3": {UnavailableStorages: []string{"local-lvm"}}}},
+			targetNode:    "pve3",
+			opts:          QemuMigrateOptions{},
+			wantErr:       true,
+		},
+		{
+			name:          "Running VM with local disks",
+			preconditions: QemuMigratePreconditions{Running: true, LocalDisks: []QemuMigrateLocalDisk{{VolId: "local-lvm:vm-100-disk-0"}}},
+			targetNode:    "pve2",
+			opts:          QemuMigrateOptions{Online: true},
+			wantErr:       true,
+		},
+		{
+			name:          "Running VM with local disks and with-local-disks",
+			preconditions: QemuMigratePreconditions{Running: true, LocalDisks: []QemuMigrateLocalDisk{{VolId: "local-lvm:vm-100-disk-0"}}},
+			targetNode:    "pve2",
+			opts:          QemuMigrateOptions{Online: true, WithLocalDisks: true},
+			wantErr:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.preconditions.Check(tt.targetNode, tt.opts)
+			if DEBUG_TESTS && err != nil {
+				t.Log(err)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QemuMigratePreconditions.Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQemu_Clone(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    QemuCloneOptions
+		wantErr bool
+	}{
+		{
+			name:    "Qemu.Clone() linked clone of non template",
+			opts:    QemuCloneOptions{NewId: TEST_PROXMOX_QEMU_VMID - 1, Name: "test-clone"},
+			wantErr: true,
+		},
+		{
+			name:    "Qemu.Clone() linked clone with storage",
+			opts:    QemuCloneOptions{NewId: TEST_PROXMOX_QEMU_VMID - 1, Storage: TEST_PROXMOX_STORAGE},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+
+			qemu, err := nodes[0].GetQemu(TEST_PROXMOX_QEMU_VMID)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			task, err := qemu.Clone(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Qemu.Clone() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("Qemu.Clone(): %v %v\n", task, err)
+			}
+		})
+	}
+}
+
+func TestQemu_ResizeDisk(t *testing.T) {
+	tests := []struct {
+		name    string
+		disk    string
+		size    string
+		stale   bool
+		wantErr bool
+	}{
+		{
+			name:    "Qemu.ResizeDisk() relative",
+			disk:    "scsi0",
+			size:    "+1G",
+			wantErr: false,
+		},
+		{
+			name:    "Qemu.ResizeDisk() stale digest",
+			disk:    "scsi0",
+			size:    "+1G",
+			stale:   true,
+			wantErr: true,
+		},
+		{
+			name:    "Qemu.ResizeDisk() wrong size",
+			disk:    "scsi0",
+			size:    "1GB",
+			wantErr: true,
+		},
+		{
+			name:    "Qemu.ResizeDisk() unused disk",
+			disk:    "unused0",
+			size:    "+1G",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := server.GetNodes()
+			if err != nil {
+				t.Log(err.Error())
+				return
+			}
+
+			qemu, err := nodes[0].GetQemu(TEST_PROXMOX_QEMU_VMID)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			digest, err := qemu.GetConfigDigest()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if tt.stale {
+				digest = strings.Repeat("0", len(digest))
+			}
+
+			task, err := qemu.ResizeDisk(tt.disk, tt.size, digest)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Qemu.ResizeDisk() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if DEBUG_TESTS {
+				t.Logf("Qemu.ResizeDisk(): %v\n", task)
+			}
+		})
+	}
+}
+
+func TestSpiceProxyInfo_VirtViewerFile(t *testing.T) {
+	tests := []struct {
+		name string
+		spi  SpiceProxyInfo
+		want string
+	}{
+		{
+			name: "SpiceProxyInfo.VirtViewerFile() test1",
+			spi: SpiceProxyInfo{
+				Type:           "spice",
+				Title:          "VM 998 - test",
+				Host:           "pvespiceproxy:abc:998:pve::def",
+				TLSPort:        61000,
+				Password:       "secret",
+				Proxy:          "http://pve:3128",
+				DeleteThisFile: 1,
+			},
+			want: "[virt-viewer]\ntype=spice\ntitle=VM 998 - test\nhost=pvespiceproxy:abc:998:pve::def\ntls-port=61000\npassword=secret\nproxy=http://pve:3128\ndelete-this-file=1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.spi.VirtViewerFile()
+
+			if DEBUG_TESTS {
+				t.Logf("%s\n", got)
+			}
+
+			if got != tt.want {
+				t.Errorf("SpiceProxyInfo.VirtViewerFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateQemuKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "ctrl-alt-delete", key: "ctrl-alt-delete", wantErr: false},
+		{name: "single key", key: "ret", wantErr: false},
+		{name: "function key", key: "alt-f4", wantErr: false},
+		{name: "keypad and hex", key: "shift-kp_5-0x1d", wantErr: false},
+		{name: "unknown key", key: "ctrl-alt-del", wantErr: true},
+		{name: "empty part", key: "ctrl--a", wantErr: true},
+		{name: "duplicate key", key: "ctrl-ctrl-a", wantErr: true},
+		{name: "empty", key: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateQemuKey(tt.key)
+
+			if DEBUG_TESTS {
+				t.Logf("%v\n", err)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateQemuKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}